@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/esnible/csv-nuds/simplenuds"
+)
+
+// checkMain() checks NUDS files, or directories of them, against the
+// subset of the NUDS schema bundled with simplenuds.  It returns the exit
+// status.
+func checkMain(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "syntax: %s check <nuds.xml or dir>...\n", os.Args[0])
+		return 3
+	}
+
+	status := 0
+
+	for _, arg := range args {
+		fileNames, err := nudsFiles(arg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return 1
+		}
+
+		for _, fileName := range fileNames {
+			if !checkFile(fileName) {
+				status = 1
+			}
+		}
+	}
+
+	return status
+}
+
+// nudsFiles() returns the .xml files in a directory, or the file itself
+func nudsFiles(name string) ([]string, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return []string{name}, nil
+	}
+
+	return filepath.Glob(filepath.Join(name, "*.xml"))
+}
+
+// checkFile() reports violations in a NUDS file, and elements the schema
+// subset does not describe, returning true if there are no violations
+func checkFile(fileName string) bool {
+	f, err := os.Open(fileName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return false
+	}
+	defer f.Close()
+
+	report, err := simplenuds.CheckSchemaXML(f)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", fileName, err)
+		return false
+	}
+
+	for _, violation := range report.Violations {
+		fmt.Printf("%s: %s\n", fileName, violation)
+	}
+
+	for _, unsupported := range report.Unsupported {
+		fmt.Printf("%s: unsupported: %s\n", fileName, unsupported)
+	}
+
+	return report.OK()
+}
@@ -0,0 +1,133 @@
+// Produce a numismatic CSV file from NUDS, for editing and converting back
+// with csv2nuds
+
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/esnible/csv-nuds/converter"
+	"github.com/esnible/csv-nuds/simplenuds"
+)
+
+func main() {
+	separator := flag.String("separator", "|", "joins the values of repeated elements, such as titles in several languages")
+	outName := flag.String("o", "", "CSV file to write (default standard output)")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		fmt.Fprintf(os.Stderr, "syntax: %s [-separator <sep>] [-o <csvname>] <nuds.xml or dir>...\n", os.Args[0])
+		os.Exit(3)
+	}
+
+	var records []map[string]string
+
+	failed := false
+
+	for _, arg := range flag.Args() {
+		names, err := nudsFiles(arg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+
+		for _, name := range names {
+			nuds, err := readNUDS(name)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", name, err)
+				failed = true
+
+				continue
+			}
+
+			records = append(records, converter.Columns(nuds, *separator))
+		}
+	}
+
+	out := os.Stdout
+
+	if *outName != "" {
+		f, err := os.Create(*outName)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		defer f.Close()
+
+		out = f
+	}
+
+	if err := writeCSV(out, records); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// nudsFiles() expands a directory into the NUDS files in it
+func nudsFiles(name string) ([]string, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return []string{name}, nil
+	}
+
+	return filepath.Glob(filepath.Join(name, "*.xml"))
+}
+
+func readNUDS(name string) (*simplenuds.NUDS, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return simplenuds.Parse(f)
+}
+
+// writeCSV() writes the records under a header of the converter's column
+// names, leaving out columns that no record has
+func writeCSV(w io.Writer, records []map[string]string) error {
+	var header []string
+
+	for _, column := range converter.ColumnNames() {
+		for _, record := range records {
+			if record[column] != "" {
+				header = append(header, column)
+				break
+			}
+		}
+	}
+
+	csvWriter := csv.NewWriter(w)
+
+	if err := csvWriter.Write(header); err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		row := make([]string, len(header))
+		for i, column := range header {
+			row[i] = record[column]
+		}
+
+		if err := csvWriter.Write(row); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/esnible/csv-nuds/converter"
+	"github.com/esnible/csv-nuds/simplenuds"
+)
+
+func TestWriteCSV(t *testing.T) {
+	ans, err := readNUDS("../../simplenuds/testdata/1922.999.73.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A record with repeated elements
+	multi := simplenuds.NUDS{
+		Control: simplenuds.Control{RecordID: "2"},
+		DescMeta: simplenuds.DescMeta{
+			Title: []simplenuds.Title{{Lang: "en", Value: "Drachm"}, {Lang: "ru", Value: "Драхма"}},
+		},
+	}
+
+	var buf bytes.Buffer
+
+	err = writeCSV(&buf, []map[string]string{
+		converter.Columns(ans, "|"),
+		converter.Columns(&multi, "|"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "id,source,rightsurl,title,date,denomination,metal,mint,diameter,weight\n" +
+		"1922.999.73,American Numismatic Society,http://opendatacommons.org/licenses/odbl/,\"Silver drahm of Khusraw II, MR, AD 591 - 628. 1922.999.73\",591 - 628,drahm,Silver,Merv,31,4.1\n" +
+		"2,,,Drachm|Драхма,,,,,,\n"
+
+	if buf.String() != expected {
+		t.Errorf("Want:\n%s\nGot:\n%s", expected, buf.String())
+	}
+}
+
+// Converting the Zeno sample with csv2nuds, exporting it with nuds2csv and
+// converting the export gives the same NUDS, apart from when it was made
+func TestRoundTrip(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds csv2nuds and nuds2csv")
+	}
+
+	dir := t.TempDir()
+	csv2nuds := filepath.Join(dir, "csv2nuds")
+	nuds2csv := filepath.Join(dir, "nuds2csv")
+	first := filepath.Join(dir, "first")
+	second := filepath.Join(dir, "second")
+	exported := filepath.Join(dir, "zeno.csv")
+
+	for _, out := range []string{first, second} {
+		if err := os.Mkdir(out, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"go", "build", "-o", csv2nuds, "../.."},
+		{"go", "build", "-o", nuds2csv, "."},
+		{csv2nuds, first, "../../data/zeno.csv", "../../data/every-zeno.csv"},
+		{nuds2csv, "-o", exported, first},
+		{csv2nuds, second, exported},
+	} {
+		// Some Zeno records have warnings, which don't matter here
+		if out, err := exec.Command(args[0], args[1:]...).CombinedOutput(); err != nil {
+			t.Fatalf("%s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+
+	// Columns the converter does not normalize come back as they were
+	original := readCSVRecords(t, "../../data/zeno.csv")
+	every := readCSVRecords(t, "../../data/every-zeno.csv")[0]
+	exportedRecords := map[string]map[string]string{}
+
+	for _, record := range readCSVRecords(t, exported) {
+		exportedRecords[record[converter.CoinID]] = record
+	}
+
+	for _, record := range original {
+		for column, val := range every {
+			record[column] = val
+		}
+
+		got := exportedRecords[record[converter.CoinID]]
+		if got == nil {
+			t.Errorf("record %s not exported", record[converter.CoinID])
+			continue
+		}
+
+		for _, column := range []string{
+			converter.CoinID, converter.URLCoin, converter.Title, converter.Diameter, converter.Source,
+			converter.CreationTime, converter.Reporter, converter.URLReporter, converter.URLRights,
+			converter.URLCoinImage, converter.AdditionalDetails,
+		} {
+			if got[column] != record[column] {
+				t.Errorf("record %s: exported %s %q, expected %q", record[converter.CoinID], column, got[column], record[column])
+			}
+		}
+	}
+
+	names, err := filepath.Glob(filepath.Join(first, "*.xml"))
+	if err != nil || len(names) == 0 {
+		t.Fatalf("no NUDS written: %v", err)
+	}
+
+	for _, name := range names {
+		want := readWithoutDerivedTime(t, name)
+		got := readWithoutDerivedTime(t, filepath.Join(second, filepath.Base(name)))
+
+		if got != want {
+			t.Errorf("%s changed after export:\nWant:\n%s\nGot:\n%s", filepath.Base(name), want, got)
+		}
+	}
+}
+
+// readWithoutDerivedTime() reads a NUDS file, leaving out when it was
+// derived from the CSV, which differs between runs.  The time the coin
+// was created comes from the CSV and is kept.
+func readWithoutDerivedTime(t *testing.T, name string) string {
+	t.Helper()
+
+	nuds, err := readNUDS(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := nuds.Control.MaintenanceHistory.MaintenanceEvent
+	for i := range events {
+		if events[i].EventType.Value == "derived" {
+			events[i].EventDateTime = simplenuds.EventDateTime{}
+		}
+	}
+
+	data, err := xml.MarshalIndent(nuds, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
+
+// readCSVRecords() reads the rows of a CSV file by lower-case header
+func readCSVRecords(t *testing.T, name string) []map[string]string {
+	t.Helper()
+
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+
+	var records []map[string]string
+
+	for _, row := range rows[1:] {
+		record := map[string]string{}
+
+		for i, val := range row {
+			if val != "" {
+				record[strings.ToLower(rows[0][i])] = val
+			}
+		}
+
+		records = append(records, record)
+	}
+
+	return records
+}
@@ -0,0 +1,241 @@
+package converter
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/esnible/csv-nuds/simplenuds"
+)
+
+var (
+	// A date as xs:gYear, xs:gYearMonth or xs:date, e.g. "1922-05-01"
+	isoDateRE = regexp.MustCompile(`^\d{4}(-\d{2}(-\d{2})?)?$`)
+
+	// Fields of one acquisition in the provenance column
+	provenanceFieldRE = regexp.MustCompile(`\s*\|\s*`)
+)
+
+// accessionHandler() records the accession number, e.g.
+//
+//	<adminDesc><identifier>1922.999.73</identifier></adminDesc>
+func accessionHandler(coin *simplenuds.NUDS, val string) error {
+	coin.DescMeta.DefaultAdminDesc().Identifier = strings.TrimSpace(val)
+
+	return nil
+}
+
+// categoryHandler() records the category of a collection such as Zeno,
+// e.g. "Sasanian Empire > Khusru II", as a <department>
+func categoryHandler(coin *simplenuds.NUDS, val string) error {
+	coin.DescMeta.DefaultAdminDesc().AppendDepartment(strings.TrimSpace(val))
+
+	return nil
+}
+
+// collectionHandler() names the collection the object belongs to
+func collectionHandler(coin *simplenuds.NUDS, val string) error {
+	coin.DescMeta.DefaultAdminDesc().Collection = &simplenuds.Collection{
+		Type:  "simple",
+		Value: strings.TrimSpace(val),
+	}
+
+	return nil
+}
+
+// repositoryHandler() names the institution that holds the object
+func repositoryHandler(coin *simplenuds.NUDS, val string) error {
+	coin.DescMeta.DefaultAdminDesc().Repository = &simplenuds.Repository{
+		Type:  "simple",
+		Value: strings.TrimSpace(val),
+	}
+
+	return nil
+}
+
+// physlocHandler() records where the object is kept, such as a cabinet
+// and tray
+func physlocHandler(coin *simplenuds.NUDS, val string) error {
+	coin.DescMeta.DefaultAdminDesc().Physloc = strings.TrimSpace(val)
+
+	return nil
+}
+
+// ownerHandler() names the owner of the object
+func ownerHandler(coin *simplenuds.NUDS, val string) error {
+	coin.DescMeta.DefaultAdminDesc().Owner = &simplenuds.Owner{
+		Type:  "simple",
+		Value: strings.TrimSpace(val),
+	}
+
+	return nil
+}
+
+// provenanceHandler() reads acquisitions separated by semicolons, each
+// written "date | from | method", e.g.
+// "1922 | Edward T. Newell | gift; 1950-03 | Stack's | purchase".  Fields
+// may be left empty.  The acquisitions become a <chronList>, earliest
+// first if every one is dated,
+//
+//	<provenance>
+//	  <chronList>
+//	    <chronItem>
+//	      <date standardDate="1922">1922</date>
+//	      <acquiredFrom xlink:type="simple">Edward T. Newell</acquiredFrom>
+//	      <event>gift</event>
+//	    </chronItem>
+//	  </chronList>
+//	</provenance>
+func provenanceHandler(coin *simplenuds.NUDS, val string) error {
+	var chronItems []simplenuds.ChronItem
+
+	var invalid []string
+
+	for _, acquisition := range strings.Split(val, ";") {
+		acquisition = strings.TrimSpace(acquisition)
+		if acquisition == "" {
+			continue
+		}
+
+		fields := provenanceFieldRE.Split(acquisition, -1)
+		if len(fields) > 3 {
+			invalid = append(invalid, acquisition)
+			continue
+		}
+
+		// Missing trailing fields are empty
+		fields = append(fields, "", "")
+
+		chronItem := simplenuds.ChronItem{Event: fields[2]}
+
+		if fields[0] != "" {
+			date, ok := provenanceDate(fields[0])
+			if !ok {
+				invalid = append(invalid, acquisition)
+			}
+
+			chronItem.Date = &date
+		}
+
+		if fields[1] != "" {
+			chronItem.AcquiredFrom = &simplenuds.AcquiredFrom{
+				Type:  "simple",
+				Value: fields[1],
+			}
+		}
+
+		if chronItem.Date == nil && chronItem.AcquiredFrom == nil && chronItem.Event == "" {
+			continue
+		}
+
+		chronItems = append(chronItems, chronItem)
+	}
+
+	if len(chronItems) > 0 {
+		dated := true
+		for _, chronItem := range chronItems {
+			dated = dated && chronItem.Date != nil && chronItem.Date.StandardDate != ""
+		}
+
+		if dated {
+			sort.SliceStable(chronItems, func(i, j int) bool {
+				return chronItems[i].Date.StandardDate < chronItems[j].Date.StandardDate
+			})
+		}
+
+		chronList := &coin.DescMeta.DefaultAdminDesc().DefaultProvenance().ChronList
+		for _, chronItem := range chronItems {
+			chronList.AppendChronItem(chronItem)
+		}
+	}
+
+	if len(invalid) > 0 {
+		return warningf(CodeInvalidProvenance, "%q is not \"date | from | method\" with a year or YYYY-MM-DD date; keeping what could be read",
+			strings.Join(invalid, "; "))
+	}
+
+	return nil
+}
+
+// provenanceDate() gives the <date> of an acquisition, a year such as
+// "1922" or "c. 1950", or a date such as "1950-03-21".  The boolean is
+// false if the date could not be understood, in which case it is kept as
+// text without a standardDate.
+func provenanceDate(val string) (simplenuds.Date, bool) {
+	if isoDateRE.MatchString(val) {
+		return simplenuds.Date{StandardDate: val, Value: val}, true
+	}
+
+	if date, _, ok := parseDate(strings.ToLower(val)); ok && date != nil {
+		return *date, true
+	}
+
+	return simplenuds.Date{Value: val}, false
+}
+
+// exportAdmin() gives the adminDesc columns the way their handlers read them
+func exportAdmin(adminDesc *simplenuds.AdminDesc) map[string][]string {
+	retval := map[string][]string{}
+	if adminDesc == nil {
+		return retval
+	}
+
+	retval[Accession] = nonEmpty(adminDesc.Identifier)
+
+	for _, department := range adminDesc.Department {
+		retval[Category] = append(retval[Category], nonEmpty(department)...)
+	}
+
+	if adminDesc.Collection != nil {
+		retval[Collection] = nonEmpty(adminDesc.Collection.Value)
+	}
+
+	if adminDesc.Repository != nil {
+		retval[Repository] = nonEmpty(adminDesc.Repository.Value)
+	}
+
+	retval[Physloc] = nonEmpty(adminDesc.Physloc)
+
+	if adminDesc.Owner != nil {
+		retval[Owner] = nonEmpty(adminDesc.Owner.Value)
+	}
+
+	// The reporter's name is exported from the creation event
+	for _, acknowledgment := range adminDesc.Acknowledgment {
+		retval[URLReporter] = append(retval[URLReporter], nonEmpty(acknowledgment.Href)...)
+	}
+
+	if adminDesc.Provenance != nil {
+		var acquisitions []string
+
+		for _, chronItem := range adminDesc.Provenance.ChronList.ChronItem {
+			var fields [3]string
+
+			if date := chronItem.Date; date != nil {
+				fields[0] = date.Value
+				if date.Certainty == "circa" {
+					fields[0] = "c. " + date.Value
+				}
+			}
+
+			if chronItem.AcquiredFrom != nil {
+				fields[1] = chronItem.AcquiredFrom.Value
+			}
+
+			fields[2] = chronItem.Event
+
+			acquisition := fields[:]
+			for len(acquisition) > 1 && acquisition[len(acquisition)-1] == "" {
+				acquisition = acquisition[:len(acquisition)-1]
+			}
+
+			acquisitions = append(acquisitions, strings.Join(acquisition, " | "))
+		}
+
+		if len(acquisitions) > 0 {
+			retval[Provenance] = []string{strings.Join(acquisitions, "; ")}
+		}
+	}
+
+	return retval
+}
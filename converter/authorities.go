@@ -0,0 +1,309 @@
+package converter
+
+import (
+	"strings"
+
+	"github.com/esnible/csv-nuds/simplenuds"
+)
+
+type ruler struct {
+	// English name, with the regnal number in Roman numerals
+	Name string
+
+	// Nomisma URI, if Nomisma has a concept for this ruler
+	HRef string
+
+	// Year (AD) of accession, for rulers whose coins are dated in regnal
+	// years.  Regnal year 1 begins in the accession year.
+	Accession int
+}
+
+// Rulers keyed by lower-case name and regnal number in Roman numerals.
+// Other spellings are found through rulerSpellings.
+var rulers = map[string]ruler{
+	"ardashir i":   {Name: "Ardashir I", Accession: 224},
+	"shapur i":     {Name: "Shapur I", Accession: 240},
+	"hormizd i":    {Name: "Hormizd I", Accession: 270},
+	"bahram i":     {Name: "Bahram I", Accession: 271},
+	"bahram ii":    {Name: "Bahram II", Accession: 274},
+	"narseh":       {Name: "Narseh", Accession: 293},
+	"hormizd ii":   {Name: "Hormizd II", Accession: 302},
+	"shapur ii":    {Name: "Shapur II", Accession: 309},
+	"ardashir ii":  {Name: "Ardashir II", Accession: 379},
+	"shapur iii":   {Name: "Shapur III", Accession: 383},
+	"bahram iv":    {Name: "Bahram IV", Accession: 388},
+	"yazdgard i":   {Name: "Yazdgard I", Accession: 399},
+	"bahram v":     {Name: "Bahram V", Accession: 420},
+	"yazdgard ii":  {Name: "Yazdgard II", Accession: 438},
+	"hormizd iii":  {Name: "Hormizd III", Accession: 457},
+	"peroz":        {Name: "Peroz", Accession: 459},
+	"balash":       {Name: "Balash", Accession: 484},
+	"kavad i":      {Name: "Kavad I", Accession: 488},
+	"jamasp":       {Name: "Jamasp", Accession: 496},
+	"khusru i":     {Name: "Khusru I", Accession: 531},
+	"hormizd iv":   {Name: "Hormizd IV", Accession: 579},
+	"bahram vi":    {Name: "Bahram VI", Accession: 590},
+	"khusru ii":    {Name: "Khusru II", Accession: 590},
+	"kavad ii":     {Name: "Kavad II", Accession: 628},
+	"ardashir iii": {Name: "Ardashir III", Accession: 628},
+	"boran":        {Name: "Boran", Accession: 630},
+	"azarmidukht":  {Name: "Azarmidukht", Accession: 630},
+	"yazdgard iii": {Name: "Yazdgard III", Accession: 632},
+
+	// Roman emperors, as linked by Online Coins of the Roman Empire
+	"augustus": {Name: "Augustus", HRef: "http://nomisma.org/id/augustus"},
+	"tiberius": {Name: "Tiberius", HRef: "http://nomisma.org/id/tiberius"},
+	"nero":     {Name: "Nero", HRef: "http://nomisma.org/id/nero"},
+	"trajan":   {Name: "Trajan", HRef: "http://nomisma.org/id/trajan"},
+	"hadrian":  {Name: "Hadrian", HRef: "http://nomisma.org/id/hadrian"},
+}
+
+// Spellings of ruler names, keyed by lower-case spelling.  Collectors
+// transliterate from Pahlavi, Greek, Arabic and Persian, so one name has
+// many spellings.
+var rulerSpellings = map[string]string{
+	"khusraw":     "khusru",
+	"khusro":      "khusru",
+	"khusrau":     "khusru",
+	"khosrow":     "khusru",
+	"khosrau":     "khusru",
+	"husraw":      "khusru",
+	"kaykhusru":   "khusru",
+	"chosroes":    "khusru",
+	"kavadh":      "kavad",
+	"kawad":       "kavad",
+	"kobad":       "kavad",
+	"qubad":       "kavad",
+	"ohrmazd":     "hormizd",
+	"hormazd":     "hormizd",
+	"hormozd":     "hormizd",
+	"varahran":    "bahram",
+	"wahram":      "bahram",
+	"vahram":      "bahram",
+	"yazdegerd":   "yazdgard",
+	"yazdgerd":    "yazdgard",
+	"yazdgird":    "yazdgard",
+	"yazdigird":   "yazdgard",
+	"yezdegerd":   "yazdgard",
+	"firuz":       "peroz",
+	"piruz":       "peroz",
+	"pirooz":      "peroz",
+	"sapor":       "shapur",
+	"shabuhr":     "shapur",
+	"ardaxshir":   "ardashir",
+	"artakhshir":  "ardashir",
+	"valash":      "balash",
+	"zamasp":      "jamasp",
+	"narses":      "narseh",
+	"buran":       "boran",
+	"purandokht":  "boran",
+	"azarmigduxt": "azarmidukht",
+}
+
+// Regnal numbers as collectors write them in Arabic numerals, e.g. "Khusru 2"
+var regnalNumerals = map[string]string{
+	"1": "i",
+	"2": "ii",
+	"3": "iii",
+	"4": "iv",
+	"5": "v",
+	"6": "vi",
+}
+
+type dynasty struct {
+	// English name
+	Name string
+
+	// Nomisma URI, if Nomisma has a concept for this dynasty or state
+	HRef string
+
+	// "famname" for a dynasty, "corpname" for a state
+	Element string
+}
+
+// Dynasties and states keyed by lower-case spelling
+var dynasties = map[string]dynasty{
+	"sasanian":         {Name: "Sasanian", Element: "famname"},
+	"sassanian":        {Name: "Sasanian", Element: "famname"},
+	"sasanid":          {Name: "Sasanian", Element: "famname"},
+	"sassanid":         {Name: "Sasanian", Element: "famname"},
+	"sasanidae":        {Name: "Sasanian", Element: "famname"},
+	"kushano-sasanian": {Name: "Kushano-Sasanian", Element: "famname"},
+	"arsacid":          {Name: "Arsacid", Element: "famname"},
+	"parthian":         {Name: "Arsacid", Element: "famname"},
+	"umayyad":          {Name: "Umayyad", Element: "famname"},
+	"abbasid":          {Name: "Abbasid", Element: "famname"},
+	"julio-claudian":   {Name: "Julio-Claudian", Element: "famname"},
+	"roman empire":     {Name: "Roman Empire", Element: "corpname"},
+	"byzantine empire": {Name: "Byzantine Empire", Element: "corpname"},
+}
+
+// dynastiesByName finds dynasties by their English names, as written by Columns()
+var dynastiesByName = func() map[string]dynasty {
+	retval := map[string]dynasty{}
+	for _, d := range dynasties {
+		retval[strings.ToLower(d.Name)] = d
+	}
+
+	return retval
+}()
+
+// getRuler() finds a ruler by any spelling of the name, with the regnal
+// number in Roman or Arabic numerals, e.g. "Khusraw II" or "Kaykhusru 2".
+// The boolean is false if the ruler is not in the table.
+func getRuler(val string) (ruler, bool) {
+	words := strings.Fields(strings.ToLower(val))
+	if len(words) == 0 {
+		return ruler{}, false
+	}
+
+	name, numeral := words, ""
+	if len(words) > 1 {
+		last := words[len(words)-1]
+		if roman, ok := regnalNumerals[last]; ok {
+			name, numeral = words[:len(words)-1], roman
+		} else if strings.Trim(last, "ivx") == "" {
+			name, numeral = words[:len(words)-1], last
+		}
+	}
+
+	key := strings.Join(name, " ")
+	if spelling, ok := rulerSpellings[key]; ok {
+		key = spelling
+	}
+
+	if numeral != "" {
+		key += " " + numeral
+	}
+
+	r, ok := rulers[key]
+
+	return r, ok
+}
+
+// getDynasty() finds a dynasty or state by its lower-case spelling or name
+func getDynasty(val string) (dynasty, bool) {
+	key := strings.ToLower(strings.TrimSpace(val))
+
+	d, ok := dynasties[key]
+	if !ok {
+		d, ok = dynastiesByName[key]
+	}
+
+	return d, ok
+}
+
+// rulerPersname() gives a <persname> with role for a ruler, with the
+// name as given if the ruler is not in the table
+func rulerPersname(val, role string) (simplenuds.Persname, bool) {
+	r, ok := getRuler(val)
+	if !ok {
+		return simplenuds.Persname{
+			Role:  role,
+			Type:  "simple",
+			Value: strings.TrimSpace(val),
+		}, false
+	}
+
+	return simplenuds.Persname{
+		Role:  role,
+		Type:  "simple",
+		Href:  r.HRef,
+		Value: r.Name,
+	}, true
+}
+
+// rulerHandler() records the ruler in whose name a coin was struck,
+// resolving variant spellings against the table of rulers, e.g.
+//
+//	<authority>
+//	  <persname xlink:role="authority" xlink:type="simple" xlink:href="http://nomisma.org/id/augustus">Augustus</persname>
+//	</authority>
+func rulerHandler(coin *simplenuds.NUDS, val string) error {
+	persname, ok := rulerPersname(val, "authority")
+
+	coin.DescMeta.TypeDesc.DefaultAuthority().AppendPersname(persname)
+
+	if !ok {
+		return warningf(CodeUnknownRuler, "unknown ruler")
+	}
+
+	return nil
+}
+
+// issuerHandler() records the official, such as a governor or moneyer,
+// responsible for a coin.  Issuers are often not rulers, so an issuer
+// not in the table is not a problem.
+func issuerHandler(coin *simplenuds.NUDS, val string) error {
+	persname, _ := rulerPersname(val, "issuer")
+
+	coin.DescMeta.TypeDesc.DefaultAuthority().AppendPersname(persname)
+
+	return nil
+}
+
+// dynastyHandler() records the dynasty as a <famname>, or the state as
+// a <corpname>, e.g.
+//
+//	<authority>
+//	  <famname xlink:role="dynasty" xlink:type="simple">Sasanian</famname>
+//	</authority>
+func dynastyHandler(coin *simplenuds.NUDS, val string) error {
+	authority := coin.DescMeta.TypeDesc.DefaultAuthority()
+
+	d, ok := getDynasty(val)
+	if !ok {
+		authority.AppendFamname(simplenuds.Famname{
+			Role:  "dynasty",
+			Type:  "simple",
+			Value: strings.TrimSpace(val),
+		})
+
+		return warningf(CodeUnknownDynasty, "unknown dynasty")
+	}
+
+	if d.Element == "corpname" {
+		authority.AppendCorpname(simplenuds.Corpname{
+			Role:  "state",
+			Type:  "simple",
+			Href:  d.HRef,
+			Value: d.Name,
+		})
+	} else {
+		authority.AppendFamname(simplenuds.Famname{
+			Role:  "dynasty",
+			Type:  "simple",
+			Href:  d.HRef,
+			Value: d.Name,
+		})
+	}
+
+	return nil
+}
+
+// exportAuthority() gives the ruler, issuer and dynasty columns the way
+// the authority handlers read them
+func exportAuthority(authority *simplenuds.Authority) (rulers, issuers, dynasties []string) {
+	if authority == nil {
+		return
+	}
+
+	for _, persname := range authority.Persname {
+		switch persname.Role {
+		case "authority":
+			rulers = append(rulers, nonEmpty(persname.Value)...)
+		case "issuer":
+			issuers = append(issuers, nonEmpty(persname.Value)...)
+		}
+	}
+
+	for _, famname := range authority.Famname {
+		dynasties = append(dynasties, nonEmpty(famname.Value)...)
+	}
+
+	for _, corpname := range authority.Corpname {
+		dynasties = append(dynasties, nonEmpty(corpname.Value)...)
+	}
+
+	return
+}
@@ -3,11 +3,13 @@
 package converter
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
-	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/esnible/csv-nuds/simplenuds"
@@ -15,83 +17,455 @@ import (
 
 type NUDSWriter func(coin *simplenuds.NUDS, val string) error
 
+// A GroupWriter adds columns that are only meaningful together, such as
+// the latitude and longitude of a findspot.  vals holds the value of each
+// column of the group, "" where the record does not have it.  Problems
+// are returned by the position of the column they are about.
+type GroupWriter func(coin *simplenuds.NUDS, vals []string) []error
+
+// A ColumnGroup is a set of columns read by one GroupWriter
+type ColumnGroup struct {
+	// Number of columns in the group
+	Size    int
+	Handler GroupWriter
+}
+
+// A GroupColumn is one of the columns of a ColumnGroup
+type GroupColumn struct {
+	Group *ColumnGroup
+
+	// Position of the column's value in the group
+	Index int
+
+	// Applied to the value before the group's handler sees it
+	transforms []transformFunc
+}
+
 const (
 	// Column names in CSV file we hope to support in v0.1.
 	// These *MUST* be lower-case here.  In the .CSV they can be any case.
-	URLCoin           = "url"
-	CoinID            = "id"
-	URLCoinImage      = "imageurl"
-	Reporter          = "reporter"
-	URLReporter       = "reporterurl"
-	Category          = "category"
-	Denomination      = "denomination"
-	Keywords          = "keywords"
-	Metal             = "metal"
-	Diameter          = "diameter"
-	Title             = "title"
-	Weight            = "weight"
-	Mint              = "mint"
-	CreationTime      = "creationtime"
-	AdditionalDetails = "additionaldetails"
-	URLRights         = "rightsurl"
-	Source            = "source"
-	Date              = "date"
+	// Other headers can be sent to these handlers with a Mapping.
+	URLCoin             = "url"
+	CoinID              = "id"
+	URLCoinImage        = "imageurl"
+	Reporter            = "reporter"
+	URLReporter         = "reporterurl"
+	Category            = "category"
+	Denomination        = "denomination"
+	Keywords            = "keywords"
+	Metal               = "metal"
+	Diameter            = "diameter"
+	Title               = "title"
+	Weight              = "weight"
+	Mint                = "mint"
+	CreationTime        = "creationtime"
+	AdditionalDetails   = "additionaldetails"
+	URLRights           = "rightsurl"
+	Source              = "source"
+	Date                = "date"
+	Ruler               = "ruler"
+	Issuer              = "issuer"
+	Dynasty             = "dynasty"
+	ObverseType         = "obverse_type"
+	ObverseLegend       = "obverse_legend"
+	ObversePortrait     = "obverse_portrait"
+	ObverseSymbol       = "obverse_symbol"
+	ReverseType         = "reverse_type"
+	ReverseLegend       = "reverse_legend"
+	ReversePortrait     = "reverse_portrait"
+	ReverseSymbol       = "reverse_symbol"
+	Reference           = "reference"
+	Findspot            = "findspot"
+	FindspotDescription = "findspot_description"
+	FindspotLat         = "findspot_lat"
+	FindspotLon         = "findspot_lon"
+	Hoard               = "hoard"
+	Accession           = "accession"
+	Collection          = "collection"
+	Repository          = "repository"
+	Physloc             = "physloc"
+	Owner               = "owner"
+	Provenance          = "provenance"
+	ObverseImage        = "obverse_image"
+	ReverseImage        = "reverse_image"
+	CombinedImage       = "combined_image"
+	URLThumbnail        = "thumbnail_url"
+	IIIFService         = "iiif_service"
 )
 
+// The order in which handlers run, so that repeatable elements such as
+// <license> and <mets:file> are always appended in the same order.
+var handlerOrder = []string{
+	CoinID,
+	URLCoin,
+	Source,
+	CreationTime,
+	Reporter,
+	URLReporter,
+	URLRights,
+	Title,
+	Date,
+	Denomination,
+	Metal,
+	Ruler,
+	Issuer,
+	Dynasty,
+	Mint,
+	ObverseLegend,
+	ObverseType,
+	ObversePortrait,
+	ObverseSymbol,
+	ReverseLegend,
+	ReverseType,
+	ReversePortrait,
+	ReverseSymbol,
+	Diameter,
+	Weight,
+	Reference,
+	Findspot,
+	FindspotDescription,
+	FindspotLat,
+	FindspotLon,
+	Hoard,
+	Accession,
+	Category,
+	Collection,
+	Repository,
+	Physloc,
+	Owner,
+	Provenance,
+	AdditionalDetails,
+	ObverseImage,
+	ReverseImage,
+	URLCoinImage,
+	CombinedImage,
+	URLThumbnail,
+	IIIFService,
+}
+
+// A Converter may be shared by many goroutines.  Its Handlers, Groups
+// and Priority must not be changed once conversion has started.
 type Converter struct {
 	// Handlers for the different column names
 	Handlers map[string]NUDSWriter
 
+	// Columns read together with others, by column name.  A group's
+	// handler runs once, in the place of the first of its columns.
+	Groups map[string]GroupColumn
+
+	// Priority of each column; lower runs first.  Columns with the same
+	// priority, or none, run in alphabetical order after those with one.
+	Priority map[string]int
+
 	// The time to use when creating records
 	Timestamp time.Time
+
+	// If true, GenerateNUDS() checks each record against the subset of the
+	// NUDS schema bundled with simplenuds
+	CheckSchema bool
+}
+
+// Report summarizes a run of conversions, from the diagnostics of each
+// record.  Create one per run with NewReport().  It is safe for concurrent
+// use.
+type Report struct {
+	mu sync.Mutex
+
+	// Number of records with each column that has no handler
+	unknownColumns map[string]int
+}
+
+func NewReport() *Report {
+	return &Report{
+		unknownColumns: map[string]int{},
+	}
+}
+
+// Add() records the diagnostics of one record
+func (report *Report) Add(diagnostics Diagnostics) {
+	report.mu.Lock()
+	defer report.mu.Unlock()
+
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Code == CodeUnknownColumn {
+			report.unknownColumns[diagnostic.Column]++
+		}
+	}
+}
+
+// UnknownColumns() returns how many records had each column with no handler
+func (report *Report) UnknownColumns() map[string]int {
+	report.mu.Lock()
+	defer report.mu.Unlock()
+
+	retval := make(map[string]int, len(report.unknownColumns))
+	for column, count := range report.unknownColumns {
+		retval[column] = count
+	}
+
+	return retval
 }
 
 func NewConverter(timestamp time.Time) Converter {
+	priority := map[string]int{}
+	for i, column := range handlerOrder {
+		priority[column] = i
+	}
+
+	findspotLocation := &ColumnGroup{Size: 2, Handler: findspotLocationHandler}
+
 	return Converter{
+		Priority: priority,
 
 		Handlers: map[string]NUDSWriter{
-			CoinID:            recordID,
-			URLCoinImage:      coinSingleURLImageHandler,
-			Denomination:      denominationHandler,
-			Metal:             metalHandler,
-			Diameter:          diameterInMMHandler,
-			Title:             titleHandler,
-			Weight:            weightHandler,
-			Mint:              mintHandler,
-			URLRights:         rightsURLHandler,
-			Source:            sourceHandler,
-			CreationTime:      recordCreatedDateHandler,
-			Reporter:          reporterHandler,
-			AdditionalDetails: detailsHandler,
-			// TODO DateHandler.  The particular dataset I used for testing
-			// had 100% invalid data for date: "?", "BBA" (a mint!), and "x2"
+			CoinID:              recordID,
+			URLCoin:             sourceURLHandler,
+			URLReporter:         reporterURLHandler,
+			URLCoinImage:        coinSingleURLImageHandler,
+			ObverseImage:        imageHandler("obverse", "reference"),
+			ReverseImage:        imageHandler("reverse", "reference"),
+			CombinedImage:       imageHandler("combined", "reference"),
+			URLThumbnail:        imageHandler("combined", "thumbnail"),
+			IIIFService:         iiifServiceHandler,
+			Denomination:        denominationHandler,
+			Metal:               metalHandler,
+			Diameter:            diameterInMMHandler,
+			Title:               titleHandler,
+			Weight:              weightHandler,
+			Mint:                mintHandler,
+			URLRights:           rightsURLHandler,
+			Source:              sourceHandler,
+			CreationTime:        recordCreatedDateHandler,
+			Reporter:            reporterHandler,
+			AdditionalDetails:   detailsHandler,
+			Ruler:               rulerHandler,
+			Issuer:              issuerHandler,
+			Dynasty:             dynastyHandler,
+			ObverseType:         sideTypeHandler(obverse),
+			ObverseLegend:       sideLegendHandler(obverse),
+			ObversePortrait:     sidePortraitHandler(obverse),
+			ObverseSymbol:       sideSymbolHandler(obverse),
+			ReverseType:         sideTypeHandler(reverse),
+			ReverseLegend:       sideLegendHandler(reverse),
+			ReversePortrait:     sidePortraitHandler(reverse),
+			ReverseSymbol:       sideSymbolHandler(reverse),
+			Reference:           referenceHandler,
+			Findspot:            findspotHandler,
+			FindspotDescription: findspotDescriptionHandler,
+			Hoard:               hoardHandler,
+			Accession:           accessionHandler,
+			Category:            categoryHandler,
+			Collection:          collectionHandler,
+			Repository:          repositoryHandler,
+			Physloc:             physlocHandler,
+			Owner:               ownerHandler,
+			Provenance:          provenanceHandler,
+			// The particular dataset I used for testing had 100% invalid
+			// data for date: "?", "BBA" (a mint!), and "x2".  Regnal years
+			// need a ruler; see Options.RegnalRuler.
+			Date: dateHandler(nil),
+		},
+		Groups: map[string]GroupColumn{
+			FindspotLat: {Group: findspotLocation, Index: 0},
+			FindspotLon: {Group: findspotLocation, Index: 1},
 		},
 		Timestamp: timestamp,
 	}
 }
 
-// GenerateNUDS() generates NUDS from a slice of column values (a CSV coin row) and optional second row
-func (converter *Converter) GenerateNUDS(coin map[string]string) (*simplenuds.NUDS, error) {
+// Options configures handlers that need more than a column's value.
+// They are given when the Converter is created, so that the columns
+// named in a Mapping get the same handlers as the columns they stand for.
+type Options struct {
+	// Extra columns for the handlers, or nil
+	Mapping *Mapping
+
+	// Ruler whose regnal years are used in the date column, e.g.
+	// "Khusru II", or ""
+	RegnalRuler string
+
+	// If not nil, image columns hold paths of files on disk
+	LocalImages *LocalImages
+}
+
+// NewConverterWithOptions() creates a Converter with handlers configured
+// by the options
+func NewConverterWithOptions(timestamp time.Time, options Options) (Converter, error) {
+	converter := NewConverter(timestamp)
+
+	if options.RegnalRuler != "" {
+		era, err := newRegnalEra(options.RegnalRuler)
+		if err != nil {
+			return Converter{}, err
+		}
+
+		converter.Handlers[Date] = dateHandler(era)
+	}
+
+	if options.LocalImages != nil {
+		if err := converter.setLocalImages(*options.LocalImages); err != nil {
+			return Converter{}, err
+		}
+	}
+
+	if options.Mapping != nil {
+		if err := converter.addMapping(options.Mapping); err != nil {
+			return Converter{}, err
+		}
+	}
+
+	return converter, nil
+}
+
+// GenerateNUDS() generates NUDS from a slice of column values (a CSV coin row) and optional second row.
+// Problems with values are returned as Diagnostics; the error is for problems that stop conversion.
+func (converter *Converter) GenerateNUDS(coin map[string]string) (*simplenuds.NUDS, Diagnostics, error) {
 	retval := simplenuds.NewNUDS("physical", converter.Timestamp)
 
-	for key, val := range coin {
+	var diagnostics Diagnostics
+
+	// addError() turns a handler's warning into a Diagnostic
+	addError := func(key, val string, err error) error {
+		var warning *Warning
+		if errors.As(err, &warning) {
+			diagnostics = append(diagnostics, Diagnostic{
+				Severity: SeverityWarning,
+				Code:     warning.Code,
+				Column:   key,
+				Value:    val,
+				Message:  warning.Message,
+			})
+
+			return nil
+		}
+
+		return err
+	}
+
+	// Groups whose handler has run
+	read := map[*ColumnGroup]bool{}
+
+	for _, key := range converter.columns(coin) {
+		val := coin[key]
+
+		if column, ok := converter.Groups[key]; ok {
+			if read[column.Group] {
+				continue
+			}
+
+			read[column.Group] = true
+
+			names, vals, err := converter.groupValues(column.Group, coin)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			for i, err := range column.Group.Handler(&retval, vals) {
+				if err = addError(names[i], vals[i], err); err != nil {
+					return nil, nil, err
+				}
+			}
+
+			continue
+		}
+
 		handler, ok := converter.Handlers[key]
 		if !ok {
-			fmt.Fprintf(os.Stderr, "no handler for %q, a %q; ignoring\n", val, key)
-
-			handler = unimplementedHandler
+			diagnostics = append(diagnostics, Diagnostic{
+				Severity: SeverityInfo,
+				Code:     CodeUnknownColumn,
+				Column:   key,
+				Value:    val,
+				Message:  "no handler; ignoring",
+			})
+
+			continue
+		}
 
-			// Suppress for next coin
-			converter.Handlers[key] = handler
+		if err := addError(key, val, handler(&retval, val)); err != nil {
+			return nil, nil, err
 		}
+	}
 
-		err := handler(&retval, val)
+	if converter.CheckSchema {
+		report, err := simplenuds.CheckSchema(&retval)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
+
+		for _, violation := range report.Violations {
+			diagnostics = append(diagnostics, Diagnostic{
+				Severity: SeverityError,
+				Code:     CodeInvalidRecord,
+				Message:  violation.String(),
+			})
+		}
+
+		// simplenuds wrote something its schemas do not describe
+		for _, unsupported := range report.Unsupported {
+			diagnostics = append(diagnostics, Diagnostic{
+				Severity: SeverityWarning,
+				Code:     CodeUnsupported,
+				Message:  unsupported.String(),
+			})
+		}
+	}
+
+	for i := range diagnostics {
+		diagnostics[i].RecordID = retval.Control.RecordID
+	}
+
+	return &retval, diagnostics, nil
+}
+
+// groupValues() returns the names and transformed values of the columns
+// of a group that the coin has
+func (converter *Converter) groupValues(group *ColumnGroup, coin map[string]string) ([]string, []string, error) {
+	names := make([]string, group.Size)
+	vals := make([]string, group.Size)
+
+	for key, val := range coin {
+		column, ok := converter.Groups[key]
+		if !ok || column.Group != group {
+			continue
+		}
+
+		for _, transform := range column.transforms {
+			var err error
+
+			if val, err = transform(val); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		names[column.Index], vals[column.Index] = key, val
+	}
+
+	return names, vals, nil
+}
+
+// columns() returns the columns of a coin in the order their handlers run
+func (converter *Converter) columns(coin map[string]string) []string {
+	keys := make([]string, 0, len(coin))
+	for key := range coin {
+		keys = append(keys, key)
 	}
 
-	return &retval, nil
+	sort.Slice(keys, func(i, j int) bool {
+		pi, oki := converter.Priority[keys[i]]
+		pj, okj := converter.Priority[keys[j]]
+
+		switch {
+		case oki && okj && pi != pj:
+			return pi < pj
+		case oki != okj:
+			return oki
+		}
+
+		return keys[i] < keys[j]
+	})
+
+	return keys
 }
 
 func recordID(coin *simplenuds.NUDS, val string) error {
@@ -112,29 +486,8 @@ func recordID(coin *simplenuds.NUDS, val string) error {
 // mets is the namespace http://www.loc.gov/METS/
 // and the mets schema seems to be http://www.loc.gov/standards/mets/mets.xsd
 func coinSingleURLImageHandler(coin *simplenuds.NUDS, val string) error {
-	// Create a file group if one doesn't exist
-	if len(coin.DefaultDigRep().FileSec.FileGrp) == 0 {
-		coin.DefaultDigRep().FileSec.FileGrp = []simplenuds.FileGrp{
-			{
-				File: []simplenuds.File{},
-				USE:  "combined", // This handler is for single URLs, so "combined"
-			},
-		}
-	}
-
-	coin.DefaultDigRep().FileSec.FileGrp[0].AppendFile(
-		simplenuds.File{
-			USE: "reference",
-			FLocat: []simplenuds.FLocat{
-				{
-					LOCTYPE: "URL",
-					Href:    val,
-				},
-			},
-		},
-	)
-
-	return nil
+	// This handler is for single URLs, so "combined"
+	return addImage(coin, "combined", "reference", val)
 }
 
 // For example,
@@ -145,9 +498,17 @@ func coinSingleURLImageHandler(coin *simplenuds.NUDS, val string) error {
 //     <typeDesc>
 //       <objectType xlink:href="http://nomisma.org/id/coin" xlink:type="simple">Coin</objectType>
 //       <denomination>drahm</denomination>
+// We link well-known denominations to Nomisma, so that "Drakhm" and "drahm"
+// are both <denomination xlink:href="http://nomisma.org/id/drachm">Drachm</denomination>
 func denominationHandler(coin *simplenuds.NUDS, val string) error {
-	// TODO produce structured data for well-known types such as drachm
-	coin.DescMeta.TypeDesc.AppendDenomination(simplenuds.Denomination(val))
+	denomination, ok := getDenomination(val)
+
+	coin.DescMeta.TypeDesc.AppendDenomination(denomination)
+
+	if !ok {
+		return warningf(CodeUnknownDenomination, "unknown denomination")
+	}
+
 	return nil
 }
 
@@ -159,13 +520,27 @@ func denominationHandler(coin *simplenuds.NUDS, val string) error {
 //     <typeDesc>
 //       <objectType xlink:href="http://nomisma.org/id/coin" xlink:type="simple">Coin</objectType>
 //       <material xlink:href="http://nomisma.org/id/ar" xlink:type="simple">Silver</material>
+//
+// Plated coins keep the core material, with the plating described in
+// <physDesc><peculiarityOfProduction>.
 func metalHandler(coin *simplenuds.NUDS, val string) error {
-	material, err := getMaterial(val)
-	if err != nil {
-		return err
+	materials, treatment, ok := getMaterials(val)
+
+	for _, material := range materials {
+		coin.DescMeta.TypeDesc.AppendMaterial(material)
 	}
 
-	coin.DescMeta.TypeDesc.AppendMaterial(material)
+	if treatment != "" {
+		coin.DescMeta.DefaultPhysDesc().AppendPeculiarityOfProduction(
+			simplenuds.PeculiarityOfProduction{
+				Value: treatment,
+			},
+		)
+	}
+
+	if !ok {
+		return warningf(CodeUnknownMaterial, "unimplemented metal")
+	}
 
 	return nil
 }
@@ -183,27 +558,37 @@ func weightHandler(coin *simplenuds.NUDS, val string) error {
 	// Rewrite European comma-separated such as "3,7"
 	val = strings.Replace(val, ",", ".", 1)
 
-	// Validate data
-	if _, err := strconv.ParseFloat(val, 32); err != nil {
-		fmt.Fprintf(os.Stderr, "invalid weight %q; ignoring\n", val)
-	}
-
 	coin.DescMeta.DefaultPhysDesc().DefaultMeasurementsSet().Weight = &simplenuds.Weight{
 		Units: "g",
 		Value: val,
 	}
 
+	// Validate data
+	if _, err := strconv.ParseFloat(val, 32); err != nil {
+		return warningf(CodeInvalidWeight, "invalid weight")
+	}
+
 	return nil
 }
 
+// For example, http://numismatics.org/collection/1922.999.73.xml has
+// <geographic>
+//   <geogname xlink:role="mint" xlink:type="simple" xlink:href="http://nomisma.org/id/merv">Merv</geogname>
+// </geographic>
+// and http://numismatics.org/collection/1960.10.1.xml has
+// <geographic>
+//   <geogname xlink:role="region" xlink:type="simple">Mashriq</geogname>
+//   <geogname xlink:role="locality" xlink:type="simple">uncertain</geogname>
+// </geographic>
 func mintHandler(coin *simplenuds.NUDS, val string) error {
-	// TODO.  nolint:godox
-	// http://numismatics.org/collection/1960.10.1.xml
-	// has
-	// <geographic>
-	//   <geogname xlink:role="region" xlink:type="simple">Mashriq</geogname>
-	//   <geogname xlink:role="locality" xlink:type="simple">uncertain</geogname>
-	// </geographic>
+	geogname, ok := getMint(val)
+
+	coin.DescMeta.TypeDesc.DefaultGeographic().AppendGeogname(geogname)
+
+	if !ok {
+		return warningf(CodeUnknownMint, "unknown mint")
+	}
+
 	return nil
 }
 
@@ -232,8 +617,7 @@ func rightsURLHandler(coin *simplenuds.NUDS, val string) error {
 	// warn and ignore if val is not an URL
 	_, err := url.ParseRequestURI(val)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "%q is not a valid URL\n", val)
-		return nil
+		return warningf(CodeInvalidURL, "not a valid URL; ignoring")
 	}
 
 	// This implementation gives the same right to data and images
@@ -273,11 +657,41 @@ func recordCreatedDateHandler(coin *simplenuds.NUDS, val string) error {
 	creationEvent := coin.Control.MaintenanceHistory.
 		GetOrCreateEventType("created")
 	creationEvent.EventDateTime.Value = val
-	creationEvent.EventDateTime.StandardDateTime = val
+
+	// standardDateTime must be an xs:dateTime
+	timestamp, err := parseTimestamp(val)
+	if err != nil {
+		return warningf(CodeInvalidTimestamp, "unparseable creation time")
+	}
+
+	creationEvent.EventDateTime.StandardDateTime = timestamp.Format(time.RFC3339)
 
 	return nil
 }
 
+// Layouts seen in creation time columns, e.g. Zeno.ru's "13 Dec 20 11:55:36 +0300"
+var timestampLayouts = []string{
+	time.RFC822Z,
+	"02 Jan 06 15:04:05 -0700",
+	time.RFC3339,
+	time.RFC1123Z,
+	time.RFC1123,
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+func parseTimestamp(val string) (time.Time, error) {
+	val = strings.TrimSpace(val)
+
+	for _, layout := range timestampLayouts {
+		if timestamp, err := time.Parse(layout, val); err == nil {
+			return timestamp, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized time %q", val)
+}
+
 // Who created the original record
 func reporterHandler(coin *simplenuds.NUDS, val string) error {
 	// Note that `AgencyName` appears on the admin screen,
@@ -297,6 +711,50 @@ func reporterHandler(coin *simplenuds.NUDS, val string) error {
 	return nil
 }
 
+// The page of the record in the source, e.g. on Zeno.ru, as the other
+// representation of the same object,
+//
+//	<otherRecordId semantic="dcterms:source">https://www.zeno.ru/showphoto.php?photo=58627</otherRecordId>
+func sourceURLHandler(coin *simplenuds.NUDS, val string) error {
+	if _, err := url.ParseRequestURI(val); err != nil {
+		return warningf(CodeInvalidURL, "not a valid URL; ignoring")
+	}
+
+	coin.Control.AppendOtherRecordID(simplenuds.OtherRecordID{
+		Semantic: "dcterms:source",
+		Value:    val,
+	})
+	coin.Control.DeclareSemantic("dcterms", "http://purl.org/dc/terms/")
+
+	return nil
+}
+
+// The page of the person who reported the object, e.g. a Zeno.ru member,
+// acknowledged by the name from reporterHandler(), which runs first,
+//
+//	<acknowledgment xlink:type="simple" xlink:href="https://www.zeno.ru/member.php?uid=1503">viur</acknowledgment>
+func reporterURLHandler(coin *simplenuds.NUDS, val string) error {
+	if _, err := url.ParseRequestURI(val); err != nil {
+		return warningf(CodeInvalidURL, "not a valid URL; ignoring")
+	}
+
+	// Without a reporter, the page is the best name there is
+	name := val
+	for _, event := range coin.Control.MaintenanceHistory.MaintenanceEvent {
+		if event.EventType.Value == "created" && event.Agent.Value != "" {
+			name = event.Agent.Value
+		}
+	}
+
+	coin.DescMeta.DefaultAdminDesc().AppendAcknowledgment(simplenuds.Acknowlegement{
+		Type:  "simple",
+		Href:  val,
+		Value: name,
+	})
+
+	return nil
+}
+
 func detailsHandler(coin *simplenuds.NUDS, val string) error {
 	coin.DescMeta.AppendDescriptionSet(
 		simplenuds.DescriptionSet{
@@ -310,40 +768,3 @@ func detailsHandler(coin *simplenuds.NUDS, val string) error {
 
 	return nil
 }
-
-func unimplementedHandler(coin *simplenuds.NUDS, val string) error {
-	return nil
-}
-
-func getMaterial(material string) (simplenuds.Material, error) {
-	HRefs := map[string]string{
-		"AR": "http://nomisma.org/id/ar",
-		"AV": "http://nomisma.org/id/av",
-		// TODO Structured types for other common metals nolint:godox
-	}
-	Texts := map[string]string{
-		"AR": "Silver",
-		"AV": "Gold",
-	}
-
-	HRef, ok := HRefs[material]
-	if !ok {
-		// Warning
-		fmt.Fprintf(os.Stderr, "unimplemented metal: %q\n", material)
-
-		return simplenuds.Material{
-			Text: material,
-		}, nil
-	}
-
-	Text, ok := Texts[material]
-	if !ok {
-		return simplenuds.Material{}, fmt.Errorf("unimplemented metal: %q", material)
-	}
-
-	return simplenuds.Material{
-		HRef: HRef,
-		Type: "simple",
-		Text: Text,
-	}, nil
-}
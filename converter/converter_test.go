@@ -1,13 +1,27 @@
 package converter
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
 	"encoding/xml"
+	"errors"
 	"flag"
+	"image"
+	"image/png"
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/esnible/csv-nuds/simplenuds"
 )
 
 var (
@@ -31,22 +45,30 @@ func TestConvert(t *testing.T) {
 				"metal":        "AR",
 				"mint":         "?",
 				"reporter":     "Ombo",
+				"reporterurl":  "https://www.zeno.ru/member.php?uid=130",
 				"rightsurl":    "https://rightsstatements.org/page/CNE/1.0/?language=en",
 				"source":       "Zeno.ru",
 				"title":        "Sasanid , Kaykhusru 2 , AR drakhme",
 				"weight":       "3.62",
+				"url":          "https://www.zeno.ru/showphoto.php?photo=264199",
 			},
 			golden: "nuds264199.xml",
 		},
 	}
 
 	converter := NewConverter(time.Time{})
+	converter.CheckSchema = true
+
 	for n, testcase := range tests {
-		nuds, err := converter.GenerateNUDS(testcase.coin)
+		nuds, diagnostics, err := converter.GenerateNUDS(testcase.coin)
 		if err != nil {
 			t.Fatalf("Failed converting test %d: %v", n, err)
 		}
 
+		if diagnostics.HasErrors() {
+			t.Fatalf("Invalid NUDS for test %d: %v", n, diagnostics)
+		}
+
 		data, err := xml.MarshalIndent(nuds, " ", "  ")
 		if err != nil {
 			log.Fatal(err)
@@ -71,7 +93,12 @@ func goldenValue(t *testing.T, goldenFile string, actual string, update bool) st
 	defer f.Close()
 
 	if update {
-		_, err := f.WriteString(actual)
+		err := f.Truncate(0)
+		if err != nil {
+			t.Fatalf("Error truncating file %s: %s", goldenPath, err)
+		}
+
+		_, err = f.WriteString(actual)
 		if err != nil {
 			t.Fatalf("Error writing to file %s: %s", goldenPath, err)
 		}
@@ -85,3 +112,1174 @@ func goldenValue(t *testing.T, goldenFile string, actual string, update bool) st
 	}
 	return string(content)
 }
+
+func TestGetMint(t *testing.T) {
+	tests := []struct {
+		val      string
+		role     string
+		text     string
+		href     string
+		resolved bool
+	}{
+		{val: "AY", role: "mint", text: "Eran-Khwarrah-Shapur", href: "http://nomisma.org/id/susa", resolved: true},
+		{val: "gd", role: "mint", text: "Jay", resolved: true},
+		{val: "BBA", role: "mint", text: "Court mint (BBA)", resolved: true},
+		{val: " Ctesiphon ", role: "mint", text: "Ctesiphon", href: "http://nomisma.org/id/ctesiphon", resolved: true},
+		{val: "?", role: "locality", text: "uncertain", resolved: true},
+		{val: "Uncertain", role: "locality", text: "uncertain", resolved: true},
+		{val: "Atlantis", role: "mint", text: "Atlantis", resolved: false},
+	}
+
+	for _, testcase := range tests {
+		geogname, ok := getMint(testcase.val)
+		if ok != testcase.resolved {
+			t.Errorf("getMint(%q) resolved=%v, want %v", testcase.val, ok, testcase.resolved)
+		}
+
+		if geogname.Role != testcase.role || geogname.Value != testcase.text || geogname.Href != testcase.href {
+			t.Errorf("getMint(%q) = %+v", testcase.val, geogname)
+		}
+	}
+}
+
+func TestDateHandler(t *testing.T) {
+	tests := []struct {
+		val     string
+		era     *regnalEra
+		want    string
+		warning string
+	}{
+		{val: "622", want: `<typeDesc><date standardDate="0622">622</date></typeDesc>`},
+		{val: "100 BC", want: `<typeDesc><date standardDate="-0100">100 BC</date></typeDesc>`},
+		{val: "AH 30", want: `<typeDesc><date standardDate="0650">AH 30</date></typeDesc>`},
+		{val: "AD 591 - 628", want: `<typeDesc><dateRange><fromDate standardDate="0591">591</fromDate>` +
+			`<toDate standardDate="0628">628</toDate></dateRange></typeDesc>`},
+		{val: "c. 100-50 BC", want: `<typeDesc><dateRange><fromDate standardDate="-0100" certainty="circa">100 BC</fromDate>` +
+			`<toDate standardDate="-0050" certainty="circa">50 BC</toDate></dateRange></typeDesc>`},
+		{val: "yr. 33", era: &regnalEra{Ruler: "Khusru II", Accession: 590}, want: `<typeDesc><dateRange>` +
+			`<fromDate standardDate="0622">622</fromDate><toDate standardDate="0623">623</toDate></dateRange>` +
+			`<dateOnObject calendar="regnal">yr. 33</dateOnObject></typeDesc>`},
+		{val: "33rd r.y.", want: `<typeDesc><dateOnObject>33rd r.y.</dateOnObject></typeDesc>`, warning: CodeRegnalDate},
+		{val: "?", want: `<typeDesc></typeDesc>`},
+		{val: "x2", want: `<typeDesc></typeDesc>`, warning: CodeInvalidDate},
+	}
+
+	for _, testcase := range tests {
+		var coin simplenuds.NUDS
+
+		err := dateHandler(testcase.era)(&coin, testcase.val)
+
+		var warning *Warning
+		if errors.As(err, &warning) {
+			if warning.Code != testcase.warning {
+				t.Errorf("date %q: warning %q, want %q", testcase.val, warning.Code, testcase.warning)
+			}
+		} else if err != nil || testcase.warning != "" {
+			t.Fatalf("date %q: error %v, want warning %q", testcase.val, err, testcase.warning)
+		}
+
+		if got := marshalElement(t, "typeDesc", coin.DescMeta.TypeDesc); got != testcase.want {
+			t.Errorf("date %q:\nwant %s\ngot  %s", testcase.val, testcase.want, got)
+		}
+	}
+
+	var coin simplenuds.NUDS
+
+	_ = dateHandler(nil)(&coin, "x2")
+
+	if len(coin.DescMeta.NoteSet) != 1 || coin.DescMeta.NoteSet[0].Note[0].Value != "Date: x2" {
+		t.Errorf("unparseable date not kept as a note: %+v", coin.DescMeta.NoteSet)
+	}
+}
+
+func TestSideHandlers(t *testing.T) {
+	converter := NewConverter(time.Time{})
+	converter.CheckSchema = true
+
+	coin := map[string]string{
+		"id":               "58627",
+		"title":            "AR drachm, Khusru II",
+		"obverse_type":     "Bust of Khusru II right, wearing winged crown",
+		"obverse_legend":   "GDH apzwt / hwslwb",
+		"obverse_portrait": "Khusru II",
+		"reverse_type":     "Fire altar flanked by two attendants",
+		"reverse_legend":   "\U00010B67\U00010B65\U00010B6E\U00010B6B\U00010B65\U00010B63",
+		"reverse_symbol":   "Star and crescent",
+	}
+
+	nuds, diagnostics, err := converter.GenerateNUDS(coin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diagnostics.HasErrors() {
+		t.Fatalf("invalid record: %v", diagnostics)
+	}
+
+	want := `<typeDesc>` +
+		`<obverse><legend><tei:div type="edition"><tei:ab>GDH apzwt</tei:ab><tei:ab>hwslwb</tei:ab></tei:div></legend>` +
+		`<type><description xml:lang="en">Bust of Khusru II right, wearing winged crown</description></type>` +
+		`<persname xlink:role="portrait" xlink:type="simple">Khusru II</persname></obverse>` +
+		`<reverse><legend scriptPhysical="Inscriptional Pahlavi">` + coin["reverse_legend"] + `</legend>` +
+		`<type><description xml:lang="en">Fire altar flanked by two attendants</description></type>` +
+		`<symbol>Star and crescent</symbol></reverse>` +
+		`</typeDesc>`
+
+	if got := marshalElement(t, "typeDesc", nuds.DescMeta.TypeDesc); got != want {
+		t.Errorf("want %s\ngot  %s", want, got)
+	}
+
+	columns := Columns(nuds, "|")
+	for column, val := range coin {
+		if columns[column] != val {
+			t.Errorf("got %s %q, expected %q", column, columns[column], val)
+		}
+	}
+}
+
+func TestGetRuler(t *testing.T) {
+	for _, val := range []string{"Khusru II", "khusraw ii", "Khosrow II", "Kaykhusru 2", "Chosroes II", " KHUSRO  2 "} {
+		if r, ok := getRuler(val); !ok || r.Name != "Khusru II" || r.Accession != 590 {
+			t.Errorf("getRuler(%q) is %+v, %v", val, r, ok)
+		}
+	}
+
+	for _, val := range []string{"Khusru", "Khusru VII", "Shahrbaraz", ""} {
+		if r, ok := getRuler(val); ok {
+			t.Errorf("getRuler(%q) is %+v, want unknown", val, r)
+		}
+	}
+}
+
+func TestAuthorityHandlers(t *testing.T) {
+	converter := NewConverter(time.Time{})
+	converter.CheckSchema = true
+
+	coin := map[string]string{
+		"id":      "58627",
+		"title":   "AR drachm, Khusru II",
+		"ruler":   "Kaykhusru 2",
+		"issuer":  "Shahrbaraz",
+		"dynasty": "Sasanid",
+	}
+
+	nuds, diagnostics, err := converter.GenerateNUDS(coin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(diagnostics) != 0 {
+		t.Errorf("unexpected diagnostics %v", diagnostics)
+	}
+
+	want := `<typeDesc><authority>` +
+		`<persname xlink:role="authority" xlink:type="simple">Khusru II</persname>` +
+		`<persname xlink:role="issuer" xlink:type="simple">Shahrbaraz</persname>` +
+		`<famname xlink:role="dynasty" xlink:type="simple">Sasanian</famname>` +
+		`</authority></typeDesc>`
+
+	if got := marshalElement(t, "typeDesc", nuds.DescMeta.TypeDesc); got != want {
+		t.Errorf("want %s\ngot  %s", want, got)
+	}
+
+	_, diagnostics, err = converter.GenerateNUDS(map[string]string{
+		"id":      "1",
+		"title":   "AR denarius",
+		"ruler":   "Agustus",
+		"dynasty": "Roman Empire",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var codes []string
+	for _, diagnostic := range diagnostics {
+		codes = append(codes, diagnostic.Code)
+	}
+
+	if strings.Join(codes, " ") != CodeUnknownRuler {
+		t.Errorf("got diagnostics %v, want %s", diagnostics, CodeUnknownRuler)
+	}
+}
+
+func TestReferenceHandler(t *testing.T) {
+	tests := []struct {
+		val     string
+		want    string
+		warning string
+	}{
+		{
+			val: "Göbl II/2; SNS 123",
+			want: `<descMeta><typeDesc></typeDesc><refDesc>` +
+				`<reference><tei:title>Göbl</tei:title><tei:idno>II/2</tei:idno></reference>` +
+				`<reference><tei:title>SNS</tei:title><tei:idno>123</tei:idno></reference>` +
+				`</refDesc></descMeta>`,
+		},
+		{
+			val: "Mitchiner, Ancient and Classical World 1076",
+			want: `<descMeta><typeDesc></typeDesc><refDesc>` +
+				`<reference>Mitchiner, Ancient and Classical World 1076</reference>` +
+				`</refDesc></descMeta>`,
+		},
+		{
+			// A number in an online corpus links to the type
+			val: "crawford 44/5",
+			want: `<descMeta><typeDesc>` +
+				`<typeSeries xlink:type="simple" xlink:href="http://nomisma.org/id/rrc">Roman Republican Coinage</typeSeries>` +
+				`<typeSeriesItem xlink:type="simple" xlink:href="http://numismatics.org/crro/id/rrc-44.5">RRC 44/5</typeSeriesItem>` +
+				`</typeDesc><refDesc>` +
+				`<reference xlink:type="simple" xlink:href="http://numismatics.org/crro/id/rrc-44.5">` +
+				`<tei:title key="http://nomisma.org/id/rrc">RRC</tei:title><tei:idno>44/5</tei:idno></reference>` +
+				`</refDesc></descMeta>`,
+		},
+		{
+			val: "RIC I (second edition) Augustus 1A http://numismatics.org/ocre/id/ric.1(2).aug.1a",
+			want: `<descMeta><typeDesc>` +
+				`<typeSeries xlink:type="simple" xlink:href="http://nomisma.org/id/ric">Roman Imperial Coinage</typeSeries>` +
+				`<typeSeriesItem xlink:type="simple" xlink:href="http://numismatics.org/ocre/id/ric.1(2).aug.1a">RIC I (second edition) Augustus 1A</typeSeriesItem>` +
+				`</typeDesc><refDesc>` +
+				`<reference xlink:type="simple" xlink:href="http://numismatics.org/ocre/id/ric.1(2).aug.1a">` +
+				`<tei:title key="http://nomisma.org/id/ric">RIC</tei:title><tei:idno>I (second edition) Augustus 1A</tei:idno></reference>` +
+				`</refDesc></descMeta>`,
+		},
+		{
+			val: "https://www.zeno.ru/showphoto.php?photo=58627",
+			want: `<descMeta><typeDesc></typeDesc><refDesc>` +
+				`<reference xlink:type="simple" xlink:href="https://www.zeno.ru/showphoto.php?photo=58627"></reference>` +
+				`</refDesc></descMeta>`,
+		},
+		{
+			val: "SNS 12 http://[::1",
+			want: `<descMeta><typeDesc></typeDesc><refDesc>` +
+				`<reference><tei:title>SNS</tei:title><tei:idno>12 http://[::1</tei:idno></reference>` +
+				`</refDesc></descMeta>`,
+			warning: CodeInvalidReference,
+		},
+		{
+			// Price has an online corpus but no Nomisma URI
+			val: "Price 3264",
+			want: `<descMeta><typeDesc>` +
+				`<typeSeries>The Coinage in the Name of Alexander the Great and Philip Arrhidaeus</typeSeries>` +
+				`<typeSeriesItem xlink:type="simple" xlink:href="http://numismatics.org/pella/id/price.3264">Price 3264</typeSeriesItem>` +
+				`</typeDesc><refDesc>` +
+				`<reference xlink:type="simple" xlink:href="http://numismatics.org/pella/id/price.3264">` +
+				`<tei:title>Price</tei:title><tei:idno>3264</tei:idno></reference>` +
+				`</refDesc></descMeta>`,
+		},
+	}
+
+	for _, testcase := range tests {
+		var coin simplenuds.NUDS
+
+		err := referenceHandler(&coin, testcase.val)
+
+		var warning *Warning
+		if errors.As(err, &warning) {
+			if warning.Code != testcase.warning {
+				t.Errorf("reference %q: warning %q, want %q", testcase.val, warning.Code, testcase.warning)
+			}
+		} else if err != nil || testcase.warning != "" {
+			t.Fatalf("reference %q: error %v, want warning %q", testcase.val, err, testcase.warning)
+		}
+
+		if got := marshalElement(t, "descMeta", coin.DescMeta); got != testcase.want {
+			t.Errorf("reference %q:\nwant %s\ngot  %s", testcase.val, testcase.want, got)
+		}
+
+		// Exported references are read back the same
+		var again simplenuds.NUDS
+
+		_ = referenceHandler(&again, exportReferences(coin.DescMeta.RefDesc)[0])
+
+		if got := marshalElement(t, "descMeta", again.DescMeta); got != testcase.want {
+			t.Errorf("reference %q after export:\nwant %s\ngot  %s", testcase.val, testcase.want, got)
+		}
+	}
+	converter := NewConverter(time.Time{})
+	converter.CheckSchema = true
+
+	_, diagnostics, err := converter.GenerateNUDS(map[string]string{
+		"id":        "1",
+		"title":     "AR denarius",
+		"reference": "RRC 44/5; RIC I (second edition) Augustus 1A http://numismatics.org/ocre/id/ric.1(2).aug.1a; Sear 123",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(diagnostics) != 0 {
+		t.Errorf("unexpected diagnostics %v", diagnostics)
+	}
+}
+
+func TestFindspotHandlers(t *testing.T) {
+	testElements(t, "findspotDesc", findspotDesc, []string{Findspot, FindspotDescription, Hoard}, []elementTest{
+		{
+			coin: map[string]string{
+				"findspot":             "Nineveh",
+				"findspot_description": "Surface find near the Nergal gate",
+				"findspot_lat":         "36.36",
+				"findspot_lon":         "43.15 E",
+				"hoard":                "igch 1739",
+			},
+			want: `<findspotDesc><findspot>` +
+				`<geogname xlink:role="findspot" xlink:type="simple">Nineveh</geogname>` +
+				`<description xml:lang="en">Surface find near the Nergal gate</description>` +
+				`<gml:location><gml:Point srsName="http://www.opengis.net/def/crs/EPSG/0/4326"><gml:pos>36.36 43.15</gml:pos></gml:Point></gml:location>` +
+				`</findspot><hoard xlink:type="simple" xlink:href="http://coinhoards.org/id/igch1739">IGCH 1739</hoard></findspotDesc>`,
+		},
+		{
+			coin: map[string]string{
+				"findspot_lat": "33,5 S",
+				"findspot_lon": "-70.6",
+				"hoard":        "http://numismatics.org/chrr/id/ESC",
+			},
+			want: `<findspotDesc><findspot>` +
+				`<gml:location><gml:Point srsName="http://www.opengis.net/def/crs/EPSG/0/4326"><gml:pos>-33.5 -70.6</gml:pos></gml:Point></gml:location>` +
+				`</findspot><hoard xlink:type="simple" xlink:href="http://numismatics.org/chrr/id/ESC"></hoard></findspotDesc>`,
+		},
+		{
+			// Swapped coordinates cannot be detected when both are in range
+			coin: map[string]string{
+				"findspot":     "Merv",
+				"findspot_lat": "61.83",
+				"findspot_lon": "97.6",
+				"hoard":        "Merv hoard",
+			},
+			want: `<findspotDesc><findspot>` +
+				`<geogname xlink:role="findspot" xlink:type="simple">Merv</geogname>` +
+				`<gml:location><gml:Point srsName="http://www.opengis.net/def/crs/EPSG/0/4326"><gml:pos>61.83 97.6</gml:pos></gml:Point></gml:location>` +
+				`</findspot><hoard>Merv hoard</hoard></findspotDesc>`,
+		},
+		{
+			// ... but can when the latitude is out of range
+			coin: map[string]string{
+				"findspot":     "Merv",
+				"findspot_lat": "97.6",
+				"findspot_lon": "61.83",
+			},
+			want: `<findspotDesc><findspot>` +
+				`<geogname xlink:role="findspot" xlink:type="simple">Merv</geogname>` +
+				`</findspot></findspotDesc>`,
+			warnings: []string{CodeInvalidCoordinate},
+		},
+		{
+			// Nothing is left of the findspot without its longitude
+			coin: map[string]string{
+				"findspot_lat": "37.6 N",
+				"findspot_lon": "61.8 N",
+			},
+			warnings: []string{CodeInvalidCoordinate},
+		},
+		{
+			coin: map[string]string{
+				"findspot":     "Merv",
+				"findspot_lat": "37.6",
+			},
+			want: `<findspotDesc><findspot>` +
+				`<geogname xlink:role="findspot" xlink:type="simple">Merv</geogname>` +
+				`</findspot></findspotDesc>`,
+			warnings: []string{CodeInvalidCoordinate},
+		},
+		{
+			coin: map[string]string{
+				"findspot_lon": "61.8 E",
+				"hoard":        "Merv hoard",
+			},
+			want:     `<findspotDesc><hoard>Merv hoard</hoard></findspotDesc>`,
+			warnings: []string{CodeInvalidCoordinate},
+		},
+	})
+
+	// Each coordinate is reported against its own column
+	converter := NewConverter(time.Time{})
+
+	_, diagnostics, err := converter.GenerateNUDS(map[string]string{"id": "1", "findspot_lat": "97.6", "findspot_lon": "61.8 N"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, diagnostic := range diagnostics {
+		got = append(got, diagnostic.Column+" "+diagnostic.Value)
+	}
+
+	if want := []string{"findspot_lat 97.6", "findspot_lon 61.8 N"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got diagnostics %v, want %v", got, want)
+	}
+}
+
+func TestAdminHandlers(t *testing.T) {
+	testElements(t, "adminDesc", adminDesc, []string{Accession, Category, Collection, Repository, Physloc, Owner, Provenance}, []elementTest{
+		{
+			coin: map[string]string{
+				"accession":  "1922.999.73",
+				"category":   "Sasanian Empire > Khusru II",
+				"collection": "American Numismatic Society",
+				"repository": "American Numismatic Society",
+				"physloc":    "Cabinet 12, tray 4",
+				"owner":      "American Numismatic Society",
+				"provenance": "1950-03 | Stack's | purchase; 1922 | Edward T. Newell | gift",
+			},
+			want: `<adminDesc><identifier>1922.999.73</identifier>` +
+				`<department>Sasanian Empire &gt; Khusru II</department>` +
+				`<collection xlink:type="simple">American Numismatic Society</collection>` +
+				`<repository xlink:type="simple">American Numismatic Society</repository>` +
+				`<physloc>Cabinet 12, tray 4</physloc>` +
+				`<owner xlink:type="simple">American Numismatic Society</owner>` +
+				`<provenance><chronList>` +
+				`<chronItem><date standardDate="1922">1922</date><acquiredFrom xlink:type="simple">Edward T. Newell</acquiredFrom><event>gift</event></chronItem>` +
+				`<chronItem><date standardDate="1950-03">1950-03</date><acquiredFrom xlink:type="simple">Stack&#39;s</acquiredFrom><event>purchase</event></chronItem>` +
+				`</chronList></provenance></adminDesc>`,
+		},
+		{
+			// Undated acquisitions keep their order
+			coin: map[string]string{
+				"provenance": "| Zeno | ; c. 1900 | Private collection",
+			},
+			want: `<adminDesc><provenance><chronList>` +
+				`<chronItem><acquiredFrom xlink:type="simple">Zeno</acquiredFrom></chronItem>` +
+				`<chronItem><date standardDate="1900" certainty="circa">1900</date><acquiredFrom xlink:type="simple">Private collection</acquiredFrom></chronItem>` +
+				`</chronList></provenance></adminDesc>`,
+		},
+		{
+			coin: map[string]string{
+				"provenance": "before the war | Newell; 1922 | Newell | gift | extra",
+			},
+			want: `<adminDesc><provenance><chronList>` +
+				`<chronItem><date>before the war</date><acquiredFrom xlink:type="simple">Newell</acquiredFrom></chronItem>` +
+				`</chronList></provenance></adminDesc>`,
+			warnings: []string{CodeInvalidProvenance},
+		},
+	})
+}
+
+// elementTest is a row and the element of its NUDS that the row should give
+type elementTest struct {
+	coin     map[string]string
+	want     string
+	warnings []string
+}
+
+func findspotDesc(nuds *simplenuds.NUDS) interface{} { return nuds.DescMeta.FindspotDesc }
+
+func adminDesc(nuds *simplenuds.NUDS) interface{} { return nuds.DescMeta.AdminDesc }
+
+// testElements() converts each row and compares the element chosen by get
+// with what the row should give.  Rows without warnings must also export
+// the columns and convert back to the same element.
+func testElements(t *testing.T, name string, get func(*simplenuds.NUDS) interface{}, columns []string, tests []elementTest) {
+	t.Helper()
+
+	converter := NewConverter(time.Time{})
+	converter.CheckSchema = true
+
+	for _, testcase := range tests {
+		testcase.coin["id"] = "1"
+		testcase.coin["title"] = "AR drachm"
+
+		nuds, diagnostics, err := converter.GenerateNUDS(testcase.coin)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var codes []string
+		for _, diagnostic := range diagnostics {
+			codes = append(codes, diagnostic.Code)
+		}
+
+		if !reflect.DeepEqual(codes, testcase.warnings) {
+			t.Errorf("%v: got diagnostics %v, want %v", testcase.coin, diagnostics, testcase.warnings)
+		}
+
+		if got := marshalElement(t, name, get(nuds)); got != testcase.want {
+			t.Errorf("%v:\nwant %s\ngot  %s", testcase.coin, testcase.want, got)
+		}
+
+		if testcase.warnings != nil {
+			continue
+		}
+
+		exported := Columns(nuds, "|")
+		for _, column := range columns {
+			if testcase.coin[column] != "" && exported[column] == "" {
+				t.Errorf("%v: %s not exported", testcase.coin, column)
+			}
+		}
+
+		again, _, err := converter.GenerateNUDS(exported)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := marshalElement(t, name, get(again)); got != testcase.want {
+			t.Errorf("%v after export:\nwant %s\ngot  %s", testcase.coin, testcase.want, got)
+		}
+	}
+}
+
+func TestImageHandlers(t *testing.T) {
+	coin := map[string]string{
+		"id":             "1922.999.73",
+		"title":          "Silver drahm of Khusraw II",
+		"obverse_image":  "http://numismatics.org/collectionimages/19001949/1922/1922.999.73.obv.width350.jpg",
+		"reverse_image":  "http://numismatics.org/collectionimages/19001949/1922/1922.999.73.rev.width350.PNG?v=2",
+		"combined_image": "https://www.zeno.ru/showphoto.php?photo=58627",
+		"thumbnail_url":  "https://zeno.ru/data/7532/thumbs/File6779_.jpg",
+		"iiif_service":   "https://images.example.org/iiif/2/1922.999.73",
+	}
+
+	want := `<digRep><mets:fileSec>` +
+		`<mets:fileGrp USE="obverse"><mets:file USE="reference" MIMETYPE="image/jpeg">` +
+		`<mets:FLocat LOCTYPE="URL" xlink:href="http://numismatics.org/collectionimages/19001949/1922/1922.999.73.obv.width350.jpg"></mets:FLocat></mets:file></mets:fileGrp>` +
+		`<mets:fileGrp USE="reverse"><mets:file USE="reference" MIMETYPE="image/png">` +
+		`<mets:FLocat LOCTYPE="URL" xlink:href="http://numismatics.org/collectionimages/19001949/1922/1922.999.73.rev.width350.PNG?v=2"></mets:FLocat></mets:file></mets:fileGrp>` +
+		`<mets:fileGrp USE="combined"><mets:file USE="reference">` +
+		`<mets:FLocat LOCTYPE="URL" xlink:href="https://www.zeno.ru/showphoto.php?photo=58627"></mets:FLocat></mets:file>` +
+		`<mets:file USE="thumbnail" MIMETYPE="image/jpeg">` +
+		`<mets:FLocat LOCTYPE="URL" xlink:href="https://zeno.ru/data/7532/thumbs/File6779_.jpg"></mets:FLocat></mets:file>` +
+		`<mets:file USE="iiif">` +
+		`<mets:FLocat LOCTYPE="URL" xlink:href="https://images.example.org/iiif/2/1922.999.73"></mets:FLocat></mets:file></mets:fileGrp>` +
+		`</mets:fileSec></digRep>`
+
+	converter := NewConverter(time.Time{})
+	converter.CheckSchema = true
+
+	nuds, diagnostics, err := converter.GenerateNUDS(coin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(diagnostics) > 0 {
+		t.Errorf("got diagnostics %v", diagnostics)
+	}
+
+	if got := marshalElement(t, "digRep", nuds.DigRep); got != want {
+		t.Errorf("want %s\ngot  %s", want, got)
+	}
+
+	// The combined image comes back as imageurl
+	columns := Columns(nuds, "|")
+	if columns[URLCoinImage] != coin[CombinedImage] {
+		t.Errorf("got imageurl %q", columns[URLCoinImage])
+	}
+
+	again, _, err := converter.GenerateNUDS(columns)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := marshalElement(t, "digRep", again.DigRep); got != want {
+		t.Errorf("after export want %s\ngot  %s", want, got)
+	}
+
+	_, diagnostics, err = converter.GenerateNUDS(map[string]string{"id": "1", "title": "AR drachm", "obverse_image": "obv.jpg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(diagnostics) != 1 || diagnostics[0].Code != CodeInvalidURL {
+		t.Errorf("got diagnostics %v for a relative image", diagnostics)
+	}
+}
+
+func TestLocalImages(t *testing.T) {
+	dir, media := t.TempDir(), filepath.Join(t.TempDir(), "media")
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 3, 2))); err != nil {
+		t.Fatal(err)
+	}
+
+	obverse := buf.Bytes()
+
+	files := map[string][]byte{
+		"1922/obv side.png": obverse,
+		"1922/rev.jpg":      []byte("not a JPEG"),
+		"1922/master.tif":   []byte("II*\x00"),
+	}
+
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := ioutil.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Aliases get the local image handlers too
+	mapping, err := ReadMapping(strings.NewReader(`{"columns": [{"handler": "imageurl", "aliases": ["photo"]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	converter, err := NewConverterWithOptions(time.Time{}, Options{
+		Mapping:     mapping,
+		LocalImages: &LocalImages{Dir: dir, MediaDir: media, BaseURL: "https://media.example.org/coins/"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	converter.CheckSchema = true
+
+	nuds, diagnostics, err := converter.GenerateNUDS(map[string]string{
+		"id":             "1922.999.73",
+		"title":          "Silver drahm of Khusraw II",
+		"obverse_image":  "1922/obv side.png",
+		"reverse_image":  "1922/rev.jpg",
+		"combined_image": "1922/master.tif",
+		"thumbnail_url":  "1922/thumb.jpg",
+		"photo":          "../secret.jpg",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var codes []string
+	for _, diagnostic := range diagnostics {
+		codes = append(codes, diagnostic.Column+" "+diagnostic.Code)
+	}
+
+	want := []string{"reverse_image unreadable-image", "photo unreadable-image", "thumbnail_url missing-image"}
+	if !reflect.DeepEqual(codes, want) {
+		t.Errorf("got diagnostics %v, want %v", diagnostics, want)
+	}
+
+	wantXML := `<digRep><mets:amdSec><mets:techMD ID="techMD1"><mets:mdWrap MDTYPE="NISOIMG"><mets:xmlData>` +
+		`<mix:mix xmlns:mix="http://www.loc.gov/mix/v20"><mix:BasicImageInformation><mix:BasicImageCharacteristics>` +
+		`<mix:imageWidth>3</mix:imageWidth><mix:imageHeight>2</mix:imageHeight>` +
+		`</mix:BasicImageCharacteristics></mix:BasicImageInformation></mix:mix>` +
+		`</mets:xmlData></mets:mdWrap></mets:techMD></mets:amdSec><mets:fileSec>` +
+		`<mets:fileGrp USE="obverse"><mets:file USE="reference" MIMETYPE="image/png" SIZE="` + strconv.Itoa(len(obverse)) +
+		`" CHECKSUM="` + sha256Hex(obverse) + `" CHECKSUMTYPE="SHA-256" ADMID="techMD1">` +
+		`<mets:FLocat LOCTYPE="URL" xlink:href="https://media.example.org/coins/1922/obv%20side.png"></mets:FLocat></mets:file></mets:fileGrp>` +
+		`<mets:fileGrp USE="combined"><mets:file USE="reference" MIMETYPE="image/tiff" SIZE="4"` +
+		` CHECKSUM="` + sha256Hex(files["1922/master.tif"]) + `" CHECKSUMTYPE="SHA-256">` +
+		`<mets:FLocat LOCTYPE="URL" xlink:href="https://media.example.org/coins/1922/master.tif"></mets:FLocat></mets:file></mets:fileGrp>` +
+		`</mets:fileSec></digRep>`
+	if got := marshalElement(t, "digRep", nuds.DigRep); got != wantXML {
+		t.Errorf("want %s\ngot  %s", wantXML, got)
+	}
+
+	// The dimensions survive reading the record back
+	data, err := xml.Marshal(nuds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := simplenuds.Parse(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := marshalElement(t, "digRep", parsed.DigRep); got != wantXML {
+		t.Errorf("parsed digRep\nwant %s\ngot  %s", wantXML, got)
+	}
+
+	// Only readable images are copied
+	copied, err := ioutil.ReadFile(filepath.Join(media, "1922", "obv side.png"))
+	if err != nil || !bytes.Equal(copied, obverse) {
+		t.Errorf("obverse not copied to the media directory: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(media, "1922", "rev.jpg")); !os.IsNotExist(err) {
+		t.Errorf("unreadable image copied: %v", err)
+	}
+
+	info, err := (&LocalImages{Dir: dir}).Ingest("1922/obv side.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Width != 3 || info.Height != 2 {
+		t.Errorf("got dimensions %dx%d, want 3x2", info.Width, info.Height)
+	}
+
+	linked := &LocalImages{Dir: dir, MediaDir: filepath.Join(t.TempDir(), "linked"), Symlink: true}
+	if _, err := linked.Ingest("1922/obv side.png"); err != nil {
+		t.Fatal(err)
+	}
+
+	if target, err := os.Readlink(filepath.Join(linked.MediaDir, "1922", "obv side.png")); err != nil || !filepath.IsAbs(target) {
+		t.Errorf("got link to %q: %v", target, err)
+	}
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func TestScriptOf(t *testing.T) {
+	for val, want := range map[string]string{
+		"GDH hwslwb":           "",
+		"ΒΑΣΙΛΕΩΣ":             "Greek",
+		"بسم الله":             "Arabic",
+		"\U00010B67\U00010B65": "Inscriptional Pahlavi",
+		"1 ΔΡ":                 "Greek",
+	} {
+		if got := scriptOf(val); got != want {
+			t.Errorf("scriptOf(%q) is %q, want %q", val, got, want)
+		}
+	}
+}
+
+// marshalElement() marshals v as an XML element named name
+func marshalElement(t *testing.T, name string, v interface{}) string {
+	t.Helper()
+
+	var sb strings.Builder
+
+	encoder := xml.NewEncoder(&sb)
+
+	err := encoder.EncodeElement(v, xml.StartElement{Name: xml.Name{Local: name}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return sb.String()
+}
+
+func TestMapping(t *testing.T) {
+	mapping, err := ReadMappingFile("../data/example-mapping.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	converter, err := NewConverterFromMapping(time.Time{}, mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nuds, _, err := converter.GenerateNUDS(map[string]string{
+		"inventory":     " 264199 ",
+		"material":      "ar ",
+		"weight (mg)":   "3620",
+		"diameter (cm)": "2,9",
+		"nominal":       "Drakhm (sic)",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if nuds.Control.RecordID != "264199" {
+		t.Errorf("record ID %q", nuds.Control.RecordID)
+	}
+
+	if got := nuds.DescMeta.TypeDesc.Material[0].HRef; got != "http://nomisma.org/id/ar" {
+		t.Errorf("material %q", got)
+	}
+
+	measurements := nuds.DescMeta.PhysDesc.MeasurementsSet
+	if measurements.Weight.Value != "3.62" || measurements.Diameter.Value != "29" {
+		t.Errorf("measurements %+v %+v", measurements.Weight, measurements.Diameter)
+	}
+
+	if got := nuds.DescMeta.TypeDesc.Denomination[0].Value; got != "Drachm" {
+		t.Errorf("denomination %q", got)
+	}
+
+	// Transforms apply only to the aliases, not the handlers' own columns
+	nuds, _, err = converter.GenerateNUDS(map[string]string{
+		"id":       "264199",
+		"weight":   "3.62",
+		"diameter": "29",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	measurements = nuds.DescMeta.PhysDesc.MeasurementsSet
+	if measurements.Weight.Value != "3.62" || measurements.Diameter.Value != "29" {
+		t.Errorf("default columns %+v %+v", measurements.Weight, measurements.Diameter)
+	}
+
+	// Aliases get the handlers configured by the options
+	mapping, err = ReadMapping(strings.NewReader(`{"columns": [{"handler": "date", "aliases": ["datum"],
+		"transforms": [{"op": "trim"}]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	converter, err = NewConverterWithOptions(time.Time{}, Options{Mapping: mapping, RegnalRuler: "Khusru II"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nuds, diagnostics, err := converter.GenerateNUDS(map[string]string{"id": "264199", "datum": " yr. 33 "})
+	if err != nil || len(diagnostics) != 0 {
+		t.Fatalf("regnal date alias: %v %v", err, diagnostics)
+	}
+
+	if dateRange := nuds.DescMeta.TypeDesc.DateRange; dateRange == nil || dateRange.FromDate.StandardDate != "0622" {
+		t.Errorf("regnal date alias: dateRange %+v", dateRange)
+	}
+
+	if _, err := NewConverterWithOptions(time.Time{}, Options{RegnalRuler: "Nobody"}); err == nil {
+		t.Errorf("expected error for unknown ruler")
+	}
+
+	// Aliases of grouped columns are read with the rest of the group
+	mapping, err = ReadMapping(strings.NewReader(`{"columns": [{"handler": "findspot_lat", "aliases": ["Breite"]},
+		{"handler": "findspot_lon", "aliases": ["Länge"], "transforms": [{"op": "replace", "pattern": "O$", "replacement": "E"}]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	converter, err = NewConverterFromMapping(time.Time{}, mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nuds, diagnostics, err = converter.GenerateNUDS(map[string]string{"id": "264199", "breite": "36.36", "länge": "43.15 O"})
+	if err != nil || len(diagnostics) != 0 {
+		t.Fatalf("coordinate aliases: %v %v", err, diagnostics)
+	}
+
+	if got := marshalElement(t, "findspotDesc", nuds.DescMeta.FindspotDesc); !strings.Contains(got, "<gml:pos>36.36 43.15</gml:pos>") {
+		t.Errorf("coordinate aliases: got %s", got)
+	}
+
+	for _, bad := range []string{
+		`{"columns": [{"handler": "nosuch", "aliases": ["x"]}]}`,
+		`{"columns": [{"handler": "weight", "transforms": [{"op": "convert", "from": "g", "to": "mm"}]}]}`,
+		`{"columns": [{"handler": "weight", "transforms": [{"op": "replace", "pattern": "("}]}]}`,
+	} {
+		mapping, err := ReadMapping(strings.NewReader(bad))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := NewConverterFromMapping(time.Time{}, mapping); err == nil {
+			t.Errorf("expected error for %s", bad)
+		}
+	}
+}
+
+func TestGetMaterials(t *testing.T) {
+	tests := []struct {
+		val       string
+		hrefs     []string
+		treatment string
+		resolved  bool
+	}{
+		{val: "AR", hrefs: []string{"http://nomisma.org/id/ar"}, resolved: true},
+		{val: " av ", hrefs: []string{"http://nomisma.org/id/av"}, resolved: true},
+		{val: "Æ", hrefs: []string{"http://nomisma.org/id/ae"}, resolved: true},
+		{val: "Billon", hrefs: []string{"http://nomisma.org/id/billon"}, resolved: true},
+		{val: "серебро", hrefs: []string{"http://nomisma.org/id/ar"}, resolved: true},
+		{val: "Silber", hrefs: []string{"http://nomisma.org/id/ar"}, resolved: true},
+		{val: "silver washed AE", hrefs: []string{"http://nomisma.org/id/ae"}, treatment: "silver washed", resolved: true},
+		{val: "Silver-plated  bronze", hrefs: []string{"http://nomisma.org/id/ae"}, treatment: "silver plated", resolved: true},
+		{val: "Tin-zinc alloy", hrefs: []string{"http://nomisma.org/id/sn", "http://nomisma.org/id/zn"}, resolved: true},
+		{val: "unobtainium", hrefs: []string{""}, resolved: false},
+	}
+
+	for _, testcase := range tests {
+		mats, treatment, ok := getMaterials(testcase.val)
+		if ok != testcase.resolved || treatment != testcase.treatment || len(mats) != len(testcase.hrefs) {
+			t.Errorf("getMaterials(%q) = %+v, %q, %v", testcase.val, mats, treatment, ok)
+			continue
+		}
+
+		for i, href := range testcase.hrefs {
+			if mats[i].HRef != href {
+				t.Errorf("getMaterials(%q)[%d] = %+v, want %s", testcase.val, i, mats[i], href)
+			}
+		}
+	}
+}
+
+func TestGetDenomination(t *testing.T) {
+	for _, val := range []string{"drachm", "Drakhm", "DRakhm", "drahm", " Drachma "} {
+		denomination, ok := getDenomination(val)
+		if !ok || denomination.HRef != "http://nomisma.org/id/drachm" || denomination.Value != "Drachm" {
+			t.Errorf("getDenomination(%q) = %+v, %v", val, denomination, ok)
+		}
+	}
+
+	if denomination, _ := getDenomination("dirham"); denomination.HRef != "http://nomisma.org/id/dirham" {
+		t.Errorf("getDenomination(dirham) = %+v", denomination)
+	}
+
+	denomination, ok := getDenomination("Half-shekel ")
+	if ok || denomination != (simplenuds.Denomination{Value: "Half-shekel"}) {
+		t.Errorf("getDenomination(Half-shekel) = %+v, %v", denomination, ok)
+	}
+}
+
+// Converting the same coin must always give the same bytes
+func TestGenerateNUDSDeterministic(t *testing.T) {
+	mapping, err := ReadMapping(strings.NewReader(`{"columns": [
+		{"handler": "imageurl", "aliases": ["photo", "image"]},
+		{"handler": "additionaldetails", "aliases": ["notes", "comments"]},
+		{"handler": "denomination", "aliases": ["nominal"]}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	converter, err := NewConverterFromMapping(time.Time{}, mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	coin := map[string]string{
+		"id":           "264199",
+		"title":        "Sasanid , Kaykhusru 2 , AR drakhme",
+		"imageurl":     "https://zeno.ru/data/2807/medium/Kaykhusru-24.jpg",
+		"photo":        "https://zeno.ru/data/2807/medium/Kaykhusru-25.jpg",
+		"image":        "https://zeno.ru/data/2807/medium/Kaykhusru-26.jpg",
+		"notes":        "first",
+		"comments":     "second",
+		"denomination": "Drakhm",
+		"nominal":      "drachm",
+		"metal":        "Tin-zinc alloy",
+		"rightsurl":    "https://rightsstatements.org/page/CNE/1.0/?language=en",
+		"source":       "Zeno.ru",
+	}
+
+	var first string
+
+	for i := 0; i < 100; i++ {
+		nuds, _, err := converter.GenerateNUDS(coin)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := xml.Marshal(nuds)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if i == 0 {
+			first = string(data)
+		} else if string(data) != first {
+			t.Fatalf("run %d differs:\n%s\n%s", i, first, data)
+		}
+	}
+}
+
+// One Converter shared by many goroutines; run with -race
+func TestGenerateNUDSConcurrent(t *testing.T) {
+	converter := NewConverter(time.Time{})
+	handlers := len(converter.Handlers)
+
+	coin := func(n int) map[string]string {
+		return map[string]string{
+			"id":          strconv.Itoa(n),
+			"url":         "https://www.zeno.ru/showphoto.php?photo=" + strconv.Itoa(n),
+			"reporterurl": "https://www.zeno.ru/member.php?uid=130",
+			"keywords":    "Sasanian",
+			"grade":       "VF",
+			"metal":       "AR",
+			"mint":        "AY",
+			"weight":      "3,62",
+			"imageurl":    "https://zeno.ru/data/2807/medium/Kaykhusru-24.jpg",
+		}
+	}
+
+	const workers, coinsPerWorker = 32, 20
+
+	want := make([]string, workers*coinsPerWorker)
+	got := make([]string, workers*coinsPerWorker)
+
+	sequential := NewConverter(time.Time{})
+
+	for n := range want {
+		nuds, _, err := sequential.GenerateNUDS(coin(n))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, _ := xml.Marshal(nuds)
+		want[n] = string(data)
+	}
+
+	report := NewReport()
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func(w int) {
+			defer wg.Done()
+
+			for i := 0; i < coinsPerWorker; i++ {
+				n := w*coinsPerWorker + i
+
+				nuds, diagnostics, err := converter.GenerateNUDS(coin(n))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				report.Add(diagnostics)
+
+				data, _ := xml.Marshal(nuds)
+				got[n] = string(data)
+			}
+		}(w)
+	}
+
+	wg.Wait()
+
+	for n := range want {
+		if got[n] != want[n] {
+			t.Errorf("coin %d differs:\n%s\n%s", n, want[n], got[n])
+		}
+	}
+
+	if len(converter.Handlers) != handlers {
+		t.Errorf("conversion changed the handler table: %d handlers, want %d", len(converter.Handlers), handlers)
+	}
+
+	unknown := report.UnknownColumns()
+	if len(unknown) != 2 || unknown["keywords"] != workers*coinsPerWorker || unknown["grade"] != workers*coinsPerWorker {
+		t.Errorf("unknown columns %v", unknown)
+	}
+}
+
+func TestGenerateNUDSDiagnostics(t *testing.T) {
+	converter := NewConverter(time.Time{})
+	converter.CheckSchema = true
+
+	_, diagnostics, err := converter.GenerateNUDS(map[string]string{
+		"id":     "58627",
+		"title":  "AY, Sasanian AR drachm, Khusru II",
+		"grade":  "VF",
+		"metal":  "unobtainium",
+		"weight": "heavy",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Diagnostics{
+		{Severity: SeverityWarning, Code: CodeUnknownMaterial, RecordID: "58627", Column: "metal", Value: "unobtainium",
+			Message: "unimplemented metal"},
+		{Severity: SeverityWarning, Code: CodeInvalidWeight, RecordID: "58627", Column: "weight", Value: "heavy",
+			Message: "invalid weight"},
+		{Severity: SeverityInfo, Code: CodeUnknownColumn, RecordID: "58627", Column: "grade",
+			Value: "VF", Message: "no handler; ignoring"},
+		{Severity: SeverityError, Code: CodeInvalidRecord, RecordID: "58627",
+			Message: `/nuds/descMeta/physDesc/measurementsSet/weight: "heavy" is not a valid xs:decimal`},
+	}
+
+	if !reflect.DeepEqual(diagnostics, want) {
+		t.Errorf("Want:\n%v\nGot:\n%v", want, diagnostics)
+	}
+
+	if !diagnostics.HasErrors() {
+		t.Error("HasErrors() is false")
+	}
+}
+
+func TestColumnsRoundTrip(t *testing.T) {
+	every := readCSV(t, "../data/every-zeno.csv")[0]
+
+	converter := NewConverter(time.Time{})
+
+	for _, coin := range readCSV(t, "../data/zeno.csv") {
+		for column, val := range every {
+			coin[column] = val
+		}
+
+		first, _, err := converter.GenerateNUDS(coin)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		columns := Columns(first, "|")
+
+		second, _, err := converter.GenerateNUDS(columns)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want, _ := xml.Marshal(first)
+		got, _ := xml.Marshal(second)
+
+		if string(got) != string(want) {
+			t.Errorf("record %s changed after export to %v:\nWant:\n%s\nGot:\n%s", coin[CoinID], columns, want, got)
+		}
+
+		// These columns are not normalized, so they come back as they were
+		for _, column := range []string{CoinID, URLCoin, Title, Diameter, Source, CreationTime, Reporter, URLReporter, URLRights, URLCoinImage, AdditionalDetails} {
+			if columns[column] != coin[column] {
+				t.Errorf("record %s: got %s %q, expected %q", coin[CoinID], column, columns[column], coin[column])
+			}
+		}
+	}
+}
+
+// The names written by Columns() must be read back as the same thing
+func TestColumnsNames(t *testing.T) {
+	for key, m := range mints {
+		if geogname, ok := getMint(m.Name); !ok || geogname.Href != m.HRef {
+			t.Errorf("mint %q (from %q) read back as %+v", m.Name, key, geogname)
+		}
+	}
+
+	for key, r := range rulers {
+		if got, ok := getRuler(r.Name); !ok || got != r {
+			t.Errorf("ruler %q (from %q) read back as %+v", r.Name, key, got)
+		}
+	}
+
+	for key, d := range dynasties {
+		if got, ok := getDynasty(d.Name); !ok || got != d {
+			t.Errorf("dynasty %q (from %q) read back as %+v", d.Name, key, got)
+		}
+	}
+
+	for key, m := range materials {
+		if mats, _, ok := getMaterials(m.Name); !ok || len(mats) != 1 || mats[0].HRef != m.HRef {
+			t.Errorf("material %q (from %q) read back as %+v", m.Name, key, mats)
+		}
+	}
+
+	for key, d := range denominations {
+		if denomination, ok := getDenomination(d.Name); !ok || denomination.HRef != d.HRef {
+			t.Errorf("denomination %q (from %q) read back as %+v", d.Name, key, denomination)
+		}
+	}
+}
+
+// readCSV() reads a CSV file into maps from lower-case header to value
+func readCSV(t *testing.T, name string) []map[string]string {
+	t.Helper()
+
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var retval []map[string]string
+
+	for _, record := range records[1:] {
+		coin := map[string]string{}
+
+		for i, val := range record {
+			if val != "" {
+				coin[strings.ToLower(records[0][i])] = val
+			}
+		}
+
+		retval = append(retval, coin)
+	}
+
+	return retval
+}
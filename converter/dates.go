@@ -0,0 +1,254 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/esnible/csv-nuds/simplenuds"
+)
+
+// Values that record that the date is not known
+var unknownDates = map[string]bool{
+	"?":       true,
+	"??":      true,
+	"unknown": true,
+	"n/a":     true,
+	"n.d.":    true,
+}
+
+var (
+	circaRE  = regexp.MustCompile(`^(circa|ca\.?|c\.|~)\s*`)
+	regnalRE = regexp.MustCompile(`^(?:(?:yr\.?|year|ry|r\.y\.)\s*(\d+)|(\d+)(?:st|nd|rd|th)?\s*(?:r\.\s*y\.?|ry|regnal year))$`)
+	rangeRE  = regexp.MustCompile(`\s*(?:-|–|—|\bto\b)\s*`)
+	yearRE   = regexp.MustCompile(`^(?:(ad|ce|bc|bce|ah)\s*)?(\d{1,4})(?:\s*(ad|ce|bc|bce|ah))?$`)
+)
+
+// regnalEra is the ruler whose regnal years are used to date coins
+type regnalEra struct {
+	Ruler     string
+	Accession int
+}
+
+// An era of a year: AD, BC or AH
+type era string
+
+const (
+	eraNone era = ""
+	eraAD   era = "ad"
+	eraBC   era = "bc"
+	eraAH   era = "ah"
+)
+
+// newRegnalEra() looks up the ruler used to interpret regnal years such
+// as "yr. 33" in the date column
+func newRegnalEra(ruler string) (*regnalEra, error) {
+	r, ok := getRuler(ruler)
+	if !ok || r.Accession == 0 {
+		return nil, fmt.Errorf("unknown ruler %q for regnal years", ruler)
+	}
+
+	return &regnalEra{
+		Ruler:     r.Name,
+		Accession: r.Accession,
+	}, nil
+}
+
+// dateHandler() returns a handler for the date column.  The handler
+// understands AD/BC/AH years, ranges, "circa", and, if era is not nil,
+// regnal years.  For example,
+// http://numismatics.org/collection/1922.999.73.xml has
+// <typeDesc>
+//
+//	<dateRange>
+//	  <fromDate standardDate="0591">591</fromDate>
+//	  <toDate standardDate="0628">628</toDate>
+//	</dateRange>
+func dateHandler(era *regnalEra) NUDSWriter {
+	return func(coin *simplenuds.NUDS, val string) error {
+		key := strings.ToLower(strings.TrimSpace(val))
+		if unknownDates[key] {
+			return nil
+		}
+
+		typeDesc := &coin.DescMeta.TypeDesc
+
+		// Regnal years
+		if m := regnalRE.FindStringSubmatch(key); m != nil {
+			if era == nil {
+				typeDesc.DateOnObject = &simplenuds.DateOnObject{Value: val}
+
+				return warningf(CodeRegnalDate, "regnal date but no ruler configured")
+			}
+
+			year, _ := strconv.Atoi(m[1] + m[2])
+			typeDesc.DateOnObject = &simplenuds.DateOnObject{
+				Calendar: "regnal",
+				Value:    val,
+			}
+			typeDesc.DateRange = &simplenuds.DateRange{
+				FromDate: newDate(era.Accession+year-1, ""),
+				ToDate:   newDate(era.Accession+year, ""),
+			}
+
+			return nil
+		}
+
+		date, dateRange, ok := parseDate(key)
+		if !ok {
+			coin.DescMeta.AppendNoteSet(simplenuds.NoteSet{
+				Note: []simplenuds.Note{
+					{
+						Value: "Date: " + val,
+					},
+				},
+			})
+
+			return warningf(CodeInvalidDate, "unparseable date; keeping as a note")
+		}
+
+		if date != nil {
+			date.Value = strings.TrimSpace(val)
+		}
+
+		typeDesc.Date = date
+		typeDesc.DateRange = dateRange
+
+		return nil
+	}
+}
+
+// parseDate() parses a lower-case year or range of years
+func parseDate(val string) (*simplenuds.Date, *simplenuds.DateRange, bool) {
+	certainty := ""
+	if loc := circaRE.FindStringIndex(val); loc != nil {
+		certainty = "circa"
+		val = val[loc[1]:]
+	}
+
+	parts := rangeRE.Split(val, -1)
+
+	switch len(parts) {
+	case 1:
+		year, ok := parseYear(parts[0], eraNone)
+		if !ok {
+			return nil, nil, false
+		}
+
+		date := newDate(year, certainty)
+
+		return &date, nil, true
+	case 2:
+		// An era on either side applies to both, e.g. "100-50 BC" or "AD 591 - 628"
+		fromEra, toEra := yearEra(parts[0]), yearEra(parts[1])
+		if fromEra == eraNone {
+			fromEra = toEra
+		}
+
+		if toEra == eraNone {
+			toEra = fromEra
+		}
+
+		from, ok := parseYear(parts[0], fromEra)
+		if !ok {
+			return nil, nil, false
+		}
+
+		to, ok := parseYear(parts[1], toEra)
+		if !ok || to < from {
+			return nil, nil, false
+		}
+
+		return nil, &simplenuds.DateRange{
+			FromDate: newDate(from, certainty),
+			ToDate:   newDate(to, certainty),
+		}, true
+	}
+
+	return nil, nil, false
+}
+
+// yearEra() returns the era written in a single year, if any
+func yearEra(val string) era {
+	m := yearRE.FindStringSubmatch(strings.TrimSpace(val))
+	if m == nil {
+		return eraNone
+	}
+
+	return normalizeEra(m[1] + m[3])
+}
+
+func normalizeEra(val string) era {
+	switch val {
+	case "bc", "bce":
+		return eraBC
+	case "ah":
+		return eraAH
+	case "ad", "ce":
+		return eraAD
+	}
+
+	return eraNone
+}
+
+// parseYear() parses a single year, returning it as AD (negative for BC).
+// If the year has no era, defaultEra is used.
+func parseYear(val string, defaultEra era) (int, bool) {
+	m := yearRE.FindStringSubmatch(strings.TrimSpace(val))
+	if m == nil {
+		return 0, false
+	}
+
+	year, err := strconv.Atoi(m[2])
+	if err != nil || year == 0 {
+		return 0, false
+	}
+
+	yearEra := normalizeEra(m[1] + m[3])
+	if yearEra == eraNone {
+		yearEra = defaultEra
+	}
+
+	switch yearEra {
+	case eraBC:
+		return -year, true
+	case eraAH:
+		return hijriToAD(year), true
+	}
+
+	return year, true
+}
+
+// hijriToAD() gives the AD year in which a Hijri year begins.  There are
+// about 33 Hijri years for every 32 AD years, and AH 1 began in AD 622.
+func hijriToAD(year int) int {
+	return 622 + (year-1)*32/33
+}
+
+// newDate() creates a <date> for an AD year, negative for BC
+func newDate(year int, certainty string) simplenuds.Date {
+	return simplenuds.Date{
+		StandardDate: standardYear(year),
+		Certainty:    certainty,
+		Value:        displayYear(year),
+	}
+}
+
+// standardYear() formats a year as xs:gYear
+func standardYear(year int) string {
+	if year < 0 {
+		return fmt.Sprintf("-%04d", -year)
+	}
+
+	return fmt.Sprintf("%04d", year)
+}
+
+// displayYear() formats a year for people to read
+func displayYear(year int) string {
+	if year < 0 {
+		return fmt.Sprintf("%d BC", -year)
+	}
+
+	return strconv.Itoa(year)
+}
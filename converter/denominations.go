@@ -0,0 +1,115 @@
+package converter
+
+import (
+	"strings"
+
+	"github.com/esnible/csv-nuds/simplenuds"
+)
+
+type denomination struct {
+	// English name, as used by Nomisma
+	Name string
+
+	// Nomisma URI
+	HRef string
+}
+
+// Nomisma denominations, see http://nomisma.org/browse?type=nmo:Denomination
+var (
+	denDrachm      = denomination{"Drachm", "http://nomisma.org/id/drachm"}
+	denHemidrachm  = denomination{"Hemidrachm", "http://nomisma.org/id/hemidrachm"}
+	denDidrachm    = denomination{"Didrachm", "http://nomisma.org/id/didrachm"}
+	denTetradrachm = denomination{"Tetradrachm", "http://nomisma.org/id/tetradrachm"}
+	denObol        = denomination{"Obol", "http://nomisma.org/id/obol"}
+	denStater      = denomination{"Stater", "http://nomisma.org/id/stater"}
+	denDirham      = denomination{"Dirham", "http://nomisma.org/id/dirham"}
+	denDinar       = denomination{"Dinar", "http://nomisma.org/id/dinar"}
+	denFals        = denomination{"Fals", "http://nomisma.org/id/fals"}
+	denDenarius    = denomination{"Denarius", "http://nomisma.org/id/denarius"}
+	denAureus      = denomination{"Aureus", "http://nomisma.org/id/aureus"}
+	denSolidus     = denomination{"Solidus", "http://nomisma.org/id/solidus"}
+	denFollis      = denomination{"Follis", "http://nomisma.org/id/follis"}
+	denSestertius  = denomination{"Sestertius", "http://nomisma.org/id/sestertius"}
+	denAs          = denomination{"As", "http://nomisma.org/id/as"}
+	denDupondius   = denomination{"Dupondius", "http://nomisma.org/id/dupondius"}
+	denAntoninian  = denomination{"Antoninianus", "http://nomisma.org/id/antoninianus"}
+	denSiliqua     = denomination{"Siliqua", "http://nomisma.org/id/siliqua"}
+	denTremissis   = denomination{"Tremissis", "http://nomisma.org/id/tremissis"}
+	denNummus      = denomination{"Nummus", "http://nomisma.org/id/nummus"}
+)
+
+// Denominations keyed by lower-case spelling.  Collectors transliterate
+// from Greek, Pahlavi, Arabic and Russian, so one denomination has many
+// spellings.
+var denominations = map[string]denomination{
+	"drachm":   denDrachm,
+	"drachms":  denDrachm,
+	"drachma":  denDrachm,
+	"drachmae": denDrachm,
+	"drachme":  denDrachm,
+	"drakhm":   denDrachm,
+	"drakhme":  denDrachm,
+	"drakhma":  denDrachm,
+	"drahm":    denDrachm,
+	"drachmë":  denDrachm,
+	"драхма":   denDrachm,
+
+	"hemidrachm":   denHemidrachm,
+	"didrachm":     denDidrachm,
+	"tetradrachm":  denTetradrachm,
+	"tetradrachma": denTetradrachm,
+	"тетрадрахма":  denTetradrachm,
+	"obol":         denObol,
+	"obolus":       denObol,
+	"обол":         denObol,
+	"stater":       denStater,
+	"статер":       denStater,
+
+	"dirham":  denDirham,
+	"dirhem":  denDirham,
+	"dirhams": denDirham,
+	"дирхам":  denDirham,
+	"dinar":   denDinar,
+	"dinare":  denDinar,
+	"динар":   denDinar,
+	"fals":    denFals,
+	"fulus":   denFals,
+	"фельс":   denFals,
+
+	"denarius":     denDenarius,
+	"denarii":      denDenarius,
+	"denar":        denDenarius,
+	"dénár":        denDenarius,
+	"денарий":      denDenarius,
+	"aureus":       denAureus,
+	"solidus":      denSolidus,
+	"follis":       denFollis,
+	"фоллис":       denFollis,
+	"sestertius":   denSestertius,
+	"sesterce":     denSestertius,
+	"as":           denAs,
+	"dupondius":    denDupondius,
+	"antoninianus": denAntoninian,
+	"antoninian":   denAntoninian,
+	"антониниан":   denAntoninian,
+	"siliqua":      denSiliqua,
+	"tremissis":    denTremissis,
+	"nummus":       denNummus,
+}
+
+// getDenomination() returns a <denomination> linked to Nomisma.  If the
+// spelling is unknown the original text is kept and the boolean is false.
+func getDenomination(val string) (simplenuds.Denomination, bool) {
+	d, ok := denominations[strings.Join(strings.Fields(strings.ToLower(val)), " ")]
+	if !ok {
+		return simplenuds.Denomination{
+			Value: strings.TrimSpace(val),
+		}, false
+	}
+
+	return simplenuds.Denomination{
+		HRef:  d.HRef,
+		Type:  "simple",
+		Value: d.Name,
+	}, true
+}
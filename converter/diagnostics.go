@@ -0,0 +1,106 @@
+package converter
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// Categories of Diagnostic.  Scripts may rely on these, so do not rename them.
+const (
+	CodeUnknownColumn       = "unknown-column"
+	CodeUnknownDenomination = "unknown-denomination"
+	CodeUnknownMaterial     = "unknown-material"
+	CodeUnknownMint         = "unknown-mint"
+	CodeUnknownRuler        = "unknown-ruler"
+	CodeUnknownDynasty      = "unknown-dynasty"
+	CodeInvalidWeight       = "invalid-weight"
+	CodeInvalidURL          = "invalid-url"
+	CodeInvalidReference    = "invalid-reference"
+	CodeInvalidTimestamp    = "invalid-timestamp"
+	CodeInvalidDate         = "invalid-date"
+	CodeInvalidCoordinate   = "invalid-coordinate"
+	CodeInvalidProvenance   = "invalid-provenance"
+	CodeMissingImage        = "missing-image"
+	CodeUnreadableImage     = "unreadable-image"
+	CodeRegnalDate          = "regnal-date-without-ruler"
+	CodeInvalidRecord       = "invalid-record"
+	CodeUnsupported         = "unsupported-by-schema"
+)
+
+// A Diagnostic is a problem found while converting one record.
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+
+	// Category of the problem, one of the Code constants
+	Code string `json:"code"`
+
+	// Line of the input where the record starts, if known
+	Line int `json:"line,omitempty"`
+
+	RecordID string `json:"recordId,omitempty"`
+	Column   string `json:"column,omitempty"`
+	Value    string `json:"value,omitempty"`
+	Message  string `json:"message"`
+}
+
+func (diagnostic Diagnostic) String() string {
+	var sb strings.Builder
+
+	sb.WriteString(string(diagnostic.Severity))
+
+	if diagnostic.Line != 0 {
+		fmt.Fprintf(&sb, ": line %d", diagnostic.Line)
+	}
+
+	if diagnostic.RecordID != "" {
+		fmt.Fprintf(&sb, ": record %s", diagnostic.RecordID)
+	}
+
+	if diagnostic.Column != "" {
+		fmt.Fprintf(&sb, ": %s %q", diagnostic.Column, diagnostic.Value)
+	}
+
+	fmt.Fprintf(&sb, ": %s [%s]", diagnostic.Message, diagnostic.Code)
+
+	return sb.String()
+}
+
+// Diagnostics for one record
+type Diagnostics []Diagnostic
+
+// HasErrors() is true if any diagnostic is an error
+func (diagnostics Diagnostics) HasErrors() bool {
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == SeverityError {
+			return true
+		}
+	}
+
+	return false
+}
+
+// A Warning is returned by a NUDSWriter when a value has a problem that
+// does not stop the conversion.  The handler should still write what it can.
+type Warning struct {
+	Code    string
+	Message string
+}
+
+func (warning *Warning) Error() string {
+	return warning.Message
+}
+
+func warningf(code string, format string, args ...interface{}) error {
+	return &Warning{
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
@@ -0,0 +1,196 @@
+package converter
+
+import (
+	"strings"
+
+	"github.com/esnible/csv-nuds/simplenuds"
+)
+
+// ColumnNames() returns the columns the converter understands, in the
+// order their handlers run
+func ColumnNames() []string {
+	return append([]string(nil), handlerOrder...)
+}
+
+// Columns() flattens a record into values for the converter's columns,
+// so that converting the values again gives the same record.  Repeatable
+// elements, such as titles in several languages, are joined with
+// separator.  Elements that no column writes are left out.
+func Columns(nuds *simplenuds.NUDS, separator string) map[string]string {
+	retval := map[string]string{}
+
+	set := func(column string, vals []string) {
+		if len(vals) > 0 {
+			retval[column] = strings.Join(vals, separator)
+		}
+	}
+
+	control := &nuds.Control
+	descMeta := &nuds.DescMeta
+	typeDesc := &descMeta.TypeDesc
+
+	set(CoinID, nonEmpty(control.RecordID))
+
+	for _, otherRecordID := range control.OtherRecordID {
+		if otherRecordID.Semantic == "dcterms:source" {
+			set(URLCoin, nonEmpty(otherRecordID.Value))
+		}
+	}
+
+	set(Source, nonEmpty(control.MaintenanceAgency.AgencyName.Value))
+
+	for _, event := range control.MaintenanceHistory.MaintenanceEvent {
+		if event.EventType.Value == "created" {
+			set(CreationTime, nonEmpty(event.EventDateTime.Value))
+			set(Reporter, nonEmpty(event.Agent.Value))
+		}
+	}
+
+	// The same license is written for data and images
+	if licenses := control.RightsStmt.License; len(licenses) > 0 {
+		set(URLRights, nonEmpty(licenses[0].Href))
+	}
+
+	var titles []string
+	for _, title := range descMeta.Title {
+		titles = append(titles, nonEmpty(title.Value)...)
+	}
+
+	set(Title, titles)
+
+	set(Date, exportDate(descMeta))
+
+	var denominations []string
+	for _, denomination := range typeDesc.Denomination {
+		denominations = append(denominations, nonEmpty(denomination.Value)...)
+	}
+
+	set(Denomination, denominations)
+
+	set(Metal, exportMetal(descMeta))
+
+	rulers, issuers, dynasties := exportAuthority(typeDesc.Authority)
+	set(Ruler, rulers)
+	set(Issuer, issuers)
+	set(Dynasty, dynasties)
+
+	if typeDesc.Geographic != nil {
+		var mints []string
+
+		for _, geogname := range typeDesc.Geographic.Geogname {
+			if geogname.Role == "mint" || (geogname.Role == "locality" && geogname.Value == "uncertain") {
+				mints = append(mints, nonEmpty(geogname.Value)...)
+			}
+		}
+
+		set(Mint, mints)
+	}
+
+	types, legends, portraits, symbols := exportSide(typeDesc.Obverse)
+	set(ObverseType, types)
+	set(ObverseLegend, legends)
+	set(ObversePortrait, portraits)
+	set(ObverseSymbol, symbols)
+
+	types, legends, portraits, symbols = exportSide(typeDesc.Reverse)
+	set(ReverseType, types)
+	set(ReverseLegend, legends)
+	set(ReversePortrait, portraits)
+	set(ReverseSymbol, symbols)
+
+	if physDesc := descMeta.PhysDesc; physDesc != nil && physDesc.MeasurementsSet != nil {
+		if diameter := physDesc.MeasurementsSet.Diameter; diameter != nil {
+			set(Diameter, nonEmpty(diameter.Value))
+		}
+
+		if weight := physDesc.MeasurementsSet.Weight; weight != nil {
+			set(Weight, nonEmpty(weight.Value))
+		}
+	}
+
+	set(Reference, exportReferences(descMeta.RefDesc))
+
+	for column, vals := range exportFindspot(descMeta.FindspotDesc) {
+		set(column, vals)
+	}
+
+	for column, vals := range exportAdmin(descMeta.AdminDesc) {
+		set(column, vals)
+	}
+
+	var details []string
+
+	for _, descriptionSet := range descMeta.DescriptionSet {
+		for _, description := range descriptionSet.Description {
+			details = append(details, nonEmpty(description.Value)...)
+		}
+	}
+
+	set(AdditionalDetails, details)
+
+	for column, vals := range exportImages(nuds.DigRep) {
+		set(column, vals)
+	}
+
+	return retval
+}
+
+// exportDate() gives the date as written on the coin if known, otherwise
+// as parsed, otherwise as kept in a note by dateHandler()
+func exportDate(descMeta *simplenuds.DescMeta) []string {
+	typeDesc := &descMeta.TypeDesc
+
+	switch {
+	case typeDesc.DateOnObject != nil:
+		return nonEmpty(typeDesc.DateOnObject.Value)
+	case typeDesc.Date != nil:
+		return nonEmpty(typeDesc.Date.Value)
+	case typeDesc.DateRange != nil:
+		from, to := typeDesc.DateRange.FromDate, typeDesc.DateRange.ToDate
+
+		prefix := ""
+		if from.Certainty == "circa" {
+			prefix = "c. "
+		}
+
+		return []string{prefix + from.Value + " - " + to.Value}
+	}
+
+	for _, noteSet := range descMeta.NoteSet {
+		for _, note := range noteSet.Note {
+			if strings.HasPrefix(note.Value, "Date: ") {
+				return []string{strings.TrimPrefix(note.Value, "Date: ")}
+			}
+		}
+	}
+
+	return nil
+}
+
+// exportMetal() writes materials the way getMaterials() reads them,
+// e.g. "silver washed Bronze" or "Tin-Zinc alloy"
+func exportMetal(descMeta *simplenuds.DescMeta) []string {
+	var names []string
+
+	for _, material := range descMeta.TypeDesc.Material {
+		names = append(names, nonEmpty(material.Text)...)
+	}
+
+	if len(names) == 2 {
+		return []string{names[0] + "-" + names[1] + " alloy"}
+	}
+
+	if len(names) == 1 && descMeta.PhysDesc != nil && len(descMeta.PhysDesc.PeculiarityOfProduction) == 1 {
+		return []string{descMeta.PhysDesc.PeculiarityOfProduction[0].Value + " " + names[0]}
+	}
+
+	return names
+}
+
+func nonEmpty(val string) []string {
+	if val == "" {
+		return nil
+	}
+
+	return []string{val}
+}
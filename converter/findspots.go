@@ -0,0 +1,197 @@
+package converter
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/esnible/csv-nuds/simplenuds"
+)
+
+// The spatial reference system of findspot coordinates, WGS 84
+const srsWGS84 = "http://www.opengis.net/def/crs/EPSG/0/4326"
+
+var (
+	// A coordinate in decimal degrees, optionally with a hemisphere,
+	// e.g. "36.36", "-43.15" or "43.15 E"
+	coordinateRE = regexp.MustCompile(`^([+-]?\d+(?:\.\d+)?)\s*°?\s*([nsewNSEW]?)$`)
+
+	// An Inventory of Greek Coin Hoards number, e.g. "IGCH 1739"
+	igchRE = regexp.MustCompile(`(?i)^igch\s*(\d{1,4})$`)
+)
+
+// findspotHandler() names the place an object was found
+//
+//	<findspotDesc>
+//	  <findspot><geogname xlink:role="findspot" xlink:type="simple">Nineveh</geogname></findspot>
+//	</findspotDesc>
+func findspotHandler(coin *simplenuds.NUDS, val string) error {
+	coin.DescMeta.DefaultFindspotDesc().DefaultFindspot().AppendGeogname(simplenuds.Geogname{
+		Role:  "findspot",
+		Type:  "simple",
+		Value: val,
+	})
+
+	return nil
+}
+
+// findspotDescriptionHandler() describes the circumstances of the find
+func findspotDescriptionHandler(coin *simplenuds.NUDS, val string) error {
+	coin.DescMeta.DefaultFindspotDesc().DefaultFindspot().AppendDescription(simplenuds.Description{
+		Lang:  "en",
+		Value: val,
+	})
+
+	return nil
+}
+
+// findspotLocationHandler() reads the latitude and longitude of the
+// findspot together, since half a position is no position
+//
+//	<gml:location>
+//	  <gml:Point srsName="http://www.opengis.net/def/crs/EPSG/0/4326"><gml:pos>36.36 43.15</gml:pos></gml:Point>
+//	</gml:location>
+func findspotLocationHandler(coin *simplenuds.NUDS, vals []string) []error {
+	errs := make([]error, 2)
+
+	lat, latErr := parseCoordinate(vals[0], "ns", 90)
+	lon, lonErr := parseCoordinate(vals[1], "ew", 180)
+
+	switch {
+	case vals[0] == "" && vals[1] == "":
+		return nil
+	case vals[0] != "" && latErr != nil:
+		errs[0] = warningf(CodeInvalidCoordinate, "latitude %v; ignoring the coordinates", latErr)
+	case vals[1] == "":
+		errs[0] = warningf(CodeInvalidCoordinate, "latitude without a longitude; ignoring")
+	}
+
+	switch {
+	case vals[1] != "" && lonErr != nil:
+		errs[1] = warningf(CodeInvalidCoordinate, "longitude %v; ignoring the coordinates", lonErr)
+	case vals[0] == "":
+		errs[1] = warningf(CodeInvalidCoordinate, "longitude without a latitude; ignoring")
+	}
+
+	if latErr != nil || lonErr != nil {
+		return errs
+	}
+
+	coin.DescMeta.DefaultFindspotDesc().DefaultFindspot().Location = &simplenuds.GMLLocation{
+		Point: simplenuds.GMLPoint{
+			SrsName: srsWGS84,
+			Pos:     lat + " " + lon,
+		},
+	}
+
+	return nil
+}
+
+// parseCoordinate() reads decimal degrees, with an optional hemisphere
+// letter from hemispheres (the second one negates), and checks that it
+// is within limit degrees of zero.  It returns the degrees as written in
+// a <gml:pos>.
+func parseCoordinate(val, hemispheres string, limit float64) (string, error) {
+	// Rewrite European comma-separated such as "36,36"
+	val = strings.Replace(strings.TrimSpace(val), ",", ".", 1)
+
+	match := coordinateRE.FindStringSubmatch(val)
+	if match == nil {
+		return "", fmt.Errorf("is not decimal degrees")
+	}
+
+	degrees, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return "", fmt.Errorf("is not decimal degrees")
+	}
+
+	if hemisphere := strings.ToLower(match[2]); hemisphere != "" {
+		if !strings.Contains(hemispheres, hemisphere) {
+			return "", fmt.Errorf("is in the wrong hemisphere")
+		}
+
+		if degrees < 0 {
+			return "", fmt.Errorf("has both a sign and a hemisphere")
+		}
+
+		if hemisphere == hemispheres[1:] {
+			degrees = -degrees
+		}
+	}
+
+	if degrees < -limit || degrees > limit {
+		return "", fmt.Errorf("is out of range -%g to %g", limit, limit)
+	}
+
+	return strconv.FormatFloat(degrees, 'f', -1, 64), nil
+}
+
+// hoardHandler() links the hoard an object was part of.  The value may be
+// the URI of the hoard, or an IGCH number, which is linked to Coin Hoards.
+// Other names are kept as text.
+//
+//	<findspotDesc>
+//	  <hoard xlink:type="simple" xlink:href="http://coinhoards.org/id/igch1739">IGCH 1739</hoard>
+//	</findspotDesc>
+func hoardHandler(coin *simplenuds.NUDS, val string) error {
+	val = strings.TrimSpace(val)
+	hoard := &simplenuds.Hoard{Value: val}
+
+	if strings.HasPrefix(val, "http://") || strings.HasPrefix(val, "https://") {
+		if _, err := url.ParseRequestURI(val); err != nil {
+			return warningf(CodeInvalidURL, "not a valid URL; ignoring")
+		}
+
+		hoard = &simplenuds.Hoard{Type: "simple", Href: val}
+	} else if match := igchRE.FindStringSubmatch(val); match != nil {
+		number, _ := strconv.Atoi(match[1])
+
+		hoard = &simplenuds.Hoard{
+			Type:  "simple",
+			Href:  fmt.Sprintf("http://coinhoards.org/id/igch%04d", number),
+			Value: fmt.Sprintf("IGCH %d", number),
+		}
+	}
+
+	coin.DescMeta.DefaultFindspotDesc().Hoard = hoard
+
+	return nil
+}
+
+// exportFindspot() gives the findspot and hoard columns the way their
+// handlers read them
+func exportFindspot(findspotDesc *simplenuds.FindspotDesc) map[string][]string {
+	retval := map[string][]string{}
+	if findspotDesc == nil {
+		return retval
+	}
+
+	if findspot := findspotDesc.Findspot; findspot != nil {
+		for _, geogname := range findspot.Geogname {
+			retval[Findspot] = append(retval[Findspot], nonEmpty(geogname.Value)...)
+		}
+
+		for _, description := range findspot.Description {
+			retval[FindspotDescription] = append(retval[FindspotDescription], nonEmpty(description.Value)...)
+		}
+
+		if findspot.Location != nil {
+			if pos := strings.Fields(findspot.Location.Point.Pos); len(pos) == 2 {
+				retval[FindspotLat] = pos[:1]
+				retval[FindspotLon] = pos[1:]
+			}
+		}
+	}
+
+	if hoard := findspotDesc.Hoard; hoard != nil {
+		if hoard.Value != "" {
+			retval[Hoard] = []string{hoard.Value}
+		} else {
+			retval[Hoard] = nonEmpty(hoard.Href)
+		}
+	}
+
+	return retval
+}
@@ -0,0 +1,114 @@
+package converter
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/esnible/csv-nuds/simplenuds"
+)
+
+// MIME types of images, keyed by lower-case file extension.  Files with
+// other extensions, such as a script that serves a photo, get no MIMETYPE.
+var imageTypes = map[string]string{
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".jpe":  "image/jpeg",
+	".png":  "image/png",
+	".gif":  "image/gif",
+	".tif":  "image/tiff",
+	".tiff": "image/tiff",
+	".webp": "image/webp",
+	".jp2":  "image/jp2",
+	".svg":  "image/svg+xml",
+	".bmp":  "image/bmp",
+}
+
+// imageHandler() adds the image to the file group for a side of the coin,
+// "obverse", "reverse" or "combined", with the USE, e.g.
+//
+//	<mets:fileGrp USE="obverse">
+//	  <mets:file USE="reference" MIMETYPE="image/jpeg">
+//	    <mets:FLocat LOCTYPE="URL" xlink:href="http://numismatics.org/collectionimages/19001949/1922/1922.999.73.obv.width350.jpg"></mets:FLocat>
+//	  </mets:file>
+//	</mets:fileGrp>
+func imageHandler(group, use string) NUDSWriter {
+	return func(coin *simplenuds.NUDS, val string) error {
+		return addImage(coin, group, use, val)
+	}
+}
+
+// iiifServiceHandler() adds the base URI of a IIIF Image API service
+// for the whole coin, which Numishare uses for deep zoom.  A service is
+// not a file, so it gets no MIMETYPE.
+func iiifServiceHandler(coin *simplenuds.NUDS, val string) error {
+	if _, err := url.ParseRequestURI(val); err != nil {
+		return warningf(CodeInvalidURL, "not a valid URL; ignoring")
+	}
+
+	coin.DefaultDigRep().FileSec.DefaultFileGrp("combined").AppendFile(simplenuds.File{
+		USE:    "iiif",
+		FLocat: []simplenuds.FLocat{{LOCTYPE: "URL", Href: val}},
+	})
+
+	return nil
+}
+
+// addImage() adds a <mets:file> for the image at a URL to the file group
+func addImage(coin *simplenuds.NUDS, group, use, val string) error {
+	if _, err := url.ParseRequestURI(val); err != nil {
+		return warningf(CodeInvalidURL, "not a valid URL; ignoring")
+	}
+
+	coin.DefaultDigRep().FileSec.DefaultFileGrp(group).AppendFile(simplenuds.File{
+		USE:      use,
+		MIMETYPE: imageType(val),
+		FLocat:   []simplenuds.FLocat{{LOCTYPE: "URL", Href: val}},
+	})
+
+	return nil
+}
+
+// imageType() infers the MIME type of an image from the extension of its
+// URL or path, ignoring any query, or "" if the extension is not known
+func imageType(val string) string {
+	if u, err := url.Parse(val); err == nil {
+		val = u.Path
+	}
+
+	return imageTypes[strings.ToLower(path.Ext(val))]
+}
+
+// exportImages() gives the image columns the way their handlers read
+// them.  Files with a USE no handler writes, such as "archive", are left
+// out.
+func exportImages(digRep *simplenuds.DigRep) map[string][]string {
+	retval := map[string][]string{}
+	if digRep == nil {
+		return retval
+	}
+
+	// Columns by file group and file USE
+	columns := map[[2]string]string{
+		{"obverse", "reference"}:  ObverseImage,
+		{"reverse", "reference"}:  ReverseImage,
+		{"combined", "reference"}: URLCoinImage,
+		{"combined", "thumbnail"}: URLThumbnail,
+		{"combined", "iiif"}:      IIIFService,
+	}
+
+	for _, fileGrp := range digRep.FileSec.FileGrp {
+		for _, file := range fileGrp.File {
+			column, ok := columns[[2]string{fileGrp.USE, file.USE}]
+			if !ok {
+				continue
+			}
+
+			for _, flocat := range file.FLocat {
+				retval[column] = append(retval[column], nonEmpty(flocat.Href)...)
+			}
+		}
+	}
+
+	return retval
+}
@@ -0,0 +1,369 @@
+package converter
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	// Decoders for the dimensions of local images
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	"github.com/esnible/csv-nuds/simplenuds"
+)
+
+// LocalImages configures image columns to hold paths of image files on
+// disk, such as master images, rather than URLs.  Columns that hold URLs
+// are still written as they are.
+type LocalImages struct {
+	// Directory that the paths in image columns are relative to.  Images
+	// must be inside it.
+	Dir string
+
+	// If not "", images are copied, or linked if Symlink, into this
+	// directory, at the same path relative to it as to Dir
+	MediaDir string
+	Symlink  bool
+
+	// URL prefix of the images, followed by their path relative to Dir,
+	// e.g. "https://media.example.org/coins/".  If "", images get file:
+	// URLs of where they are, in MediaDir if set.
+	BaseURL string
+}
+
+// ImageInfo describes an image file
+type ImageInfo struct {
+	// Path relative to LocalImages.Dir, with forward slashes
+	Path string
+
+	// Size in bytes
+	Size int64
+
+	// SHA-256 of the contents, in hexadecimal
+	SHA256 string
+
+	// MIME type, from the contents if they are a GIF, JPEG or PNG,
+	// otherwise from the extension
+	MIMEType string
+
+	// Dimensions in pixels, or 0 if the format has no decoder here, such
+	// as TIFF
+	Width, Height int
+}
+
+// MIME types of the formats that image.DecodeConfig() knows
+var decodedImageTypes = map[string]string{
+	"gif":  "image/gif",
+	"jpeg": "image/jpeg",
+	"png":  "image/png",
+}
+
+// decodable() is true if image.DecodeConfig() knows the MIME type
+func decodable(mimeType string) bool {
+	for _, t := range decodedImageTypes {
+		if t == mimeType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setLocalImages() makes the image columns read files on disk.  Each file
+// gets its size and SHA-256 checksum in the <mets:file>, and its
+// dimensions, if known, in a <mets:techMD>.  A missing or unreadable image
+// is reported for the record.
+func (converter *Converter) setLocalImages(images LocalImages) error {
+	info, err := os.Stat(images.Dir)
+	if err != nil {
+		return fmt.Errorf("image directory: %w", err)
+	}
+
+	if !info.IsDir() {
+		return fmt.Errorf("image directory %s is not a directory", images.Dir)
+	}
+
+	if images.BaseURL != "" {
+		if _, err := url.ParseRequestURI(images.BaseURL); err != nil {
+			return fmt.Errorf("image base URL: %w", err)
+		}
+	}
+
+	if images.MediaDir != "" {
+		if err := os.MkdirAll(images.MediaDir, 0755); err != nil {
+			return fmt.Errorf("media directory: %w", err)
+		}
+	}
+
+	converter.Handlers[ObverseImage] = localImageHandler(&images, "obverse", "reference")
+	converter.Handlers[ReverseImage] = localImageHandler(&images, "reverse", "reference")
+	converter.Handlers[URLCoinImage] = localImageHandler(&images, "combined", "reference")
+	converter.Handlers[CombinedImage] = localImageHandler(&images, "combined", "reference")
+	converter.Handlers[URLThumbnail] = localImageHandler(&images, "combined", "thumbnail")
+
+	return nil
+}
+
+// localImageHandler() adds the image at a path, or a URL, to the file
+// group with the USE.  METS has no attributes for the dimensions of an
+// image, so they are written as MIX in the <mets:amdSec>, e.g.
+//
+//	<mets:amdSec>
+//	  <mets:techMD ID="techMD1">
+//	    <mets:mdWrap MDTYPE="NISOIMG">
+//	      <mets:xmlData>
+//	        <mix:mix xmlns:mix="http://www.loc.gov/mix/v20">...<mix:imageWidth>1200</mix:imageWidth>...</mix:mix>
+//	      </mets:xmlData>
+//	    </mets:mdWrap>
+//	  </mets:techMD>
+//	</mets:amdSec>
+//	<mets:fileSec>
+//	  <mets:fileGrp USE="obverse">
+//	    <mets:file USE="reference" MIMETYPE="image/jpeg" SIZE="48213" CHECKSUM="9f86d0..." CHECKSUMTYPE="SHA-256" ADMID="techMD1">
+//	      <mets:FLocat LOCTYPE="URL" xlink:href="https://media.example.org/coins/1922.999.73.obv.jpg"></mets:FLocat>
+//	    </mets:file>
+//	  </mets:fileGrp>
+//	</mets:fileSec>
+func localImageHandler(images *LocalImages, group, use string) NUDSWriter {
+	return func(coin *simplenuds.NUDS, val string) error {
+		if strings.HasPrefix(val, "http://") || strings.HasPrefix(val, "https://") {
+			return addImage(coin, group, use, val)
+		}
+
+		info, err := images.Ingest(val)
+		if errors.Is(err, fs.ErrNotExist) {
+			return warningf(CodeMissingImage, "no such image in %s; ignoring", images.Dir)
+		} else if err != nil {
+			return warningf(CodeUnreadableImage, "%v; ignoring", err)
+		}
+
+		digRep := coin.DefaultDigRep()
+
+		file := simplenuds.File{
+			USE:          use,
+			MIMETYPE:     info.MIMEType,
+			SIZE:         info.Size,
+			CHECKSUM:     info.SHA256,
+			CHECKSUMTYPE: "SHA-256",
+			FLocat:       []simplenuds.FLocat{{LOCTYPE: "URL", Href: images.URL(info)}},
+		}
+
+		if info.Width > 0 && info.Height > 0 {
+			file.ADMID = digRep.AppendTechMD(simplenuds.NewImageTechMD(info.Width, info.Height))
+		}
+
+		digRep.FileSec.DefaultFileGrp(group).AppendFile(file)
+
+		return nil
+	}
+}
+
+// Ingest() reads the image at a path relative to Dir, and copies or links
+// it into MediaDir if set
+func (images *LocalImages) Ingest(name string) (ImageInfo, error) {
+	rel, err := images.relative(name)
+	if err != nil {
+		return ImageInfo{}, err
+	}
+
+	source := filepath.Join(images.Dir, filepath.FromSlash(rel))
+
+	// Master images can be hundreds of megabytes, so they are read as a
+	// stream rather than all at once
+	f, err := os.Open(source)
+	if err != nil {
+		return ImageInfo{}, err
+	}
+	defer f.Close()
+
+	size, sum, err := hashFile(f)
+	if err != nil {
+		return ImageInfo{}, err
+	}
+
+	info := ImageInfo{
+		Path:     rel,
+		Size:     size,
+		SHA256:   sum,
+		MIMEType: imageType(rel),
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return ImageInfo{}, err
+	}
+
+	// DecodeConfig() reads only the header
+	config, format, err := image.DecodeConfig(bufio.NewReader(f))
+
+	switch {
+	case err == nil:
+		info.MIMEType = decodedImageTypes[format]
+		info.Width, info.Height = config.Width, config.Height
+	case info.MIMEType == "" || decodable(info.MIMEType):
+		// Not an image, or a GIF, JPEG or PNG that is damaged
+		return ImageInfo{}, fmt.Errorf("not a readable image: %w", err)
+	}
+
+	if images.MediaDir != "" {
+		if err := images.publish(source, rel, info); err != nil {
+			return ImageInfo{}, err
+		}
+	}
+
+	return info, nil
+}
+
+// URL() gives the URL of an ingested image
+func (images *LocalImages) URL(info ImageInfo) string {
+	escaped := (&url.URL{Path: info.Path}).EscapedPath()
+
+	if images.BaseURL != "" {
+		return strings.TrimSuffix(images.BaseURL, "/") + "/" + escaped
+	}
+
+	dir := images.Dir
+	if images.MediaDir != "" {
+		dir = images.MediaDir
+	}
+
+	abs, err := filepath.Abs(filepath.Join(dir, filepath.FromSlash(info.Path)))
+	if err != nil {
+		abs = filepath.Join(dir, filepath.FromSlash(info.Path))
+	}
+
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}).String()
+}
+
+// relative() gives the path of an image relative to Dir, with forward
+// slashes, refusing paths outside Dir
+func (images *LocalImages) relative(name string) (string, error) {
+	name = filepath.FromSlash(strings.TrimSpace(name))
+
+	if filepath.IsAbs(name) {
+		dir, err := filepath.Abs(images.Dir)
+		if err != nil {
+			return "", err
+		}
+
+		name, err = filepath.Rel(dir, name)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	rel := path.Clean(filepath.ToSlash(name))
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", fmt.Errorf("not in the image directory %s", images.Dir)
+	}
+
+	return rel, nil
+}
+
+// publish() copies or links the image at source into MediaDir.  An image
+// that is already there with the same contents is left alone, so that
+// records sharing an image, or converted again, do not copy it again.
+func (images *LocalImages) publish(source, rel string, info ImageInfo) error {
+	target := filepath.Join(images.MediaDir, filepath.FromSlash(rel))
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+
+	if images.Symlink {
+		abs, err := filepath.Abs(source)
+		if err != nil {
+			return err
+		}
+
+		if existing, err := os.Readlink(target); err == nil && existing == abs {
+			return nil
+		}
+
+		// Replace whatever is there in one step, through a name no other
+		// record converted at the same time uses
+		tmp, err := os.CreateTemp(filepath.Dir(target), ".tmp-"+filepath.Base(target))
+		if err != nil {
+			return err
+		}
+
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+
+		if err := os.Symlink(abs, tmp.Name()); err != nil {
+			return err
+		}
+
+		return os.Rename(tmp.Name(), target)
+	}
+
+	if existing, err := os.Lstat(target); err == nil && existing.Mode().IsRegular() && existing.Size() == info.Size {
+		if f, err := os.Open(target); err == nil {
+			_, sum, err := hashFile(f)
+			_ = f.Close()
+
+			if err == nil && sum == info.SHA256 {
+				return nil
+			}
+		}
+	}
+
+	return copyFile(source, target)
+}
+
+// hashFile() gives the size and SHA-256, in hexadecimal, of what is left
+// to read of the file
+func hashFile(f *os.File) (int64, string, error) {
+	hash := sha256.New()
+
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// copyFile() copies source to target through a temporary file, so that
+// target is never half written
+func copyFile(source, target string) error {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.CreateTemp(filepath.Dir(target), ".tmp-"+filepath.Base(target))
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Chmod(out.Name(), 0644)
+	}
+
+	if err == nil {
+		err = os.Rename(out.Name(), target)
+	}
+
+	if err != nil {
+		_ = os.Remove(out.Name())
+	}
+
+	return err
+}
@@ -0,0 +1,239 @@
+package converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/esnible/csv-nuds/simplenuds"
+)
+
+// Mapping describes how the columns of a spreadsheet map to the
+// converter's handlers.  It is usually read from a JSON file such as
+//
+//	{
+//	  "columns": [
+//	    {"handler": "id", "aliases": ["Inventory No.", "inv"]},
+//	    {"handler": "weight", "aliases": ["Weight (mg)"],
+//	     "transforms": [{"op": "convert", "from": "mg", "to": "g"}]},
+//	    {"handler": "metal", "aliases": ["Material"],
+//	     "transforms": [{"op": "trim"}, {"op": "replace", "pattern": "^Ag$", "replacement": "AR"}]}
+//	  ]
+//	}
+type Mapping struct {
+	Columns []ColumnMapping `json:"columns"`
+}
+
+// ColumnMapping sends one or more CSV columns to a handler.
+type ColumnMapping struct {
+	// Name of the handler, one of the column name constants such as "weight"
+	Handler string `json:"handler"`
+
+	// CSV headers that should go to the handler.  Case is ignored.
+	Aliases []string `json:"aliases"`
+
+	// Transforms applied in order to the values of the aliases before the
+	// handler sees them
+	Transforms []Transform `json:"transforms,omitempty"`
+}
+
+// Transform rewrites a column value.  Op is one of
+//   - "trim": remove leading and trailing whitespace
+//   - "lowercase", "uppercase": change case
+//   - "replace": replace matches of the regular expression Pattern with Replacement
+//   - "convert": convert a number from unit From to unit To, e.g. "mg" to "g"
+type Transform struct {
+	Op          string `json:"op"`
+	Pattern     string `json:"pattern,omitempty"`
+	Replacement string `json:"replacement,omitempty"`
+	From        string `json:"from,omitempty"`
+	To          string `json:"to,omitempty"`
+}
+
+// Units for "convert", as a factor to grams (mass) or millimeters (length)
+var unitFactors = map[string]struct {
+	Dimension string
+	Factor    float64
+}{
+	"mg": {"mass", 0.001},
+	"g":  {"mass", 1},
+	"kg": {"mass", 1000},
+	"gr": {"mass", 0.06479891}, // grain
+	"oz": {"mass", 28.349523125},
+	"mm": {"length", 1},
+	"cm": {"length", 10},
+	"in": {"length", 25.4},
+}
+
+type transformFunc func(val string) (string, error)
+
+// ReadMapping() reads a JSON column mapping
+func ReadMapping(r io.Reader) (*Mapping, error) {
+	var mapping Mapping
+
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&mapping); err != nil {
+		return nil, fmt.Errorf("invalid column mapping: %w", err)
+	}
+
+	return &mapping, nil
+}
+
+// ReadMappingFile() reads a JSON column mapping from a file
+func ReadMappingFile(fileName string) (*Mapping, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ReadMapping(f)
+}
+
+// NewConverterFromMapping() creates a Converter whose handlers also accept
+// the columns named in the mapping.
+func NewConverterFromMapping(timestamp time.Time, mapping *Mapping) (Converter, error) {
+	return NewConverterWithOptions(timestamp, Options{Mapping: mapping})
+}
+
+// addMapping() sends the aliases in the mapping to their handlers.
+// Transforms apply only to the aliases, so that a column with the
+// handler's own name, such as "weight" in grams, is read as usual.
+func (converter *Converter) addMapping(mapping *Mapping) error {
+	// Look up handlers by name before adding aliases, so a mapping cannot
+	// refer to an alias defined by another mapping.
+	registered := map[string]NUDSWriter{}
+	for name, handler := range converter.Handlers {
+		registered[name] = handler
+	}
+
+	registeredGroups := map[string]GroupColumn{}
+	for name, column := range converter.Groups {
+		registeredGroups[name] = column
+	}
+
+	for _, column := range mapping.Columns {
+		handler, ok := registered[strings.ToLower(column.Handler)]
+		groupColumn, inGroup := registeredGroups[strings.ToLower(column.Handler)]
+
+		if !ok && !inGroup {
+			return fmt.Errorf("column mapping: unknown handler %q", column.Handler)
+		}
+
+		transforms := make([]transformFunc, 0, len(column.Transforms))
+
+		for _, transform := range column.Transforms {
+			fn, err := transform.compile()
+			if err != nil {
+				return fmt.Errorf("column mapping for %q: %w", column.Handler, err)
+			}
+
+			transforms = append(transforms, fn)
+		}
+
+		if inGroup {
+			groupColumn.transforms = transforms
+		} else if len(transforms) > 0 {
+			handler = transformedHandler(handler, transforms)
+		}
+
+		priority, hasPriority := converter.Priority[strings.ToLower(column.Handler)]
+
+		for _, alias := range column.Aliases {
+			alias = strings.ToLower(strings.TrimSpace(alias))
+
+			if inGroup {
+				converter.Groups[alias] = groupColumn
+			} else {
+				converter.Handlers[alias] = handler
+			}
+
+			// Aliases run alongside the column they stand for
+			if hasPriority {
+				converter.Priority[alias] = priority
+			}
+		}
+	}
+
+	return nil
+}
+
+// transformedHandler() wraps a handler so the value is transformed first
+func transformedHandler(handler NUDSWriter, transforms []transformFunc) NUDSWriter {
+	return func(coin *simplenuds.NUDS, val string) error {
+		for _, transform := range transforms {
+			var err error
+
+			val, err = transform(val)
+			if err != nil {
+				return err
+			}
+		}
+
+		return handler(coin, val)
+	}
+}
+
+func (transform Transform) compile() (transformFunc, error) {
+	switch transform.Op {
+	case "trim":
+		return func(val string) (string, error) {
+			return strings.TrimSpace(val), nil
+		}, nil
+	case "lowercase":
+		return func(val string) (string, error) {
+			return strings.ToLower(val), nil
+		}, nil
+	case "uppercase":
+		return func(val string) (string, error) {
+			return strings.ToUpper(val), nil
+		}, nil
+	case "replace":
+		re, err := regexp.Compile(transform.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", transform.Pattern, err)
+		}
+
+		return func(val string) (string, error) {
+			return re.ReplaceAllString(val, transform.Replacement), nil
+		}, nil
+	case "convert":
+		from, ok := unitFactors[strings.ToLower(transform.From)]
+		if !ok {
+			return nil, fmt.Errorf("unknown unit %q", transform.From)
+		}
+
+		to, ok := unitFactors[strings.ToLower(transform.To)]
+		if !ok {
+			return nil, fmt.Errorf("unknown unit %q", transform.To)
+		}
+
+		if from.Dimension != to.Dimension {
+			return nil, fmt.Errorf("cannot convert %q to %q", transform.From, transform.To)
+		}
+
+		factor := from.Factor / to.Factor
+
+		return func(val string) (string, error) {
+			// Accept European decimal commas such as "3,7"
+			number, err := strconv.ParseFloat(strings.Replace(strings.TrimSpace(val), ",", ".", 1), 64)
+			if err != nil {
+				// Leave the value alone; the handler will warn about it
+				return val, nil // nolint: nilerr
+			}
+
+			// Round away floating point noise such as 3.6199999999999997
+			return strconv.FormatFloat(math.Round(number*factor*1e6)/1e6, 'f', -1, 64), nil
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown transform %q", transform.Op)
+}
@@ -0,0 +1,187 @@
+package converter
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/esnible/csv-nuds/simplenuds"
+)
+
+type material struct {
+	// English name, as used by Nomisma
+	Name string
+
+	// Nomisma URI
+	HRef string
+}
+
+// Nomisma material concepts, see http://nomisma.org/browse?type=nmo:Material
+var (
+	matSilver      = material{"Silver", "http://nomisma.org/id/ar"}
+	matGold        = material{"Gold", "http://nomisma.org/id/av"}
+	matCopperAlloy = material{"Bronze", "http://nomisma.org/id/ae"}
+	matCopper      = material{"Copper", "http://nomisma.org/id/cu"}
+	matBrass       = material{"Brass", "http://nomisma.org/id/brass"}
+	matBillon      = material{"Billon", "http://nomisma.org/id/billon"}
+	matElectrum    = material{"Electrum", "http://nomisma.org/id/el"}
+	matLead        = material{"Lead", "http://nomisma.org/id/pb"}
+	matTin         = material{"Tin", "http://nomisma.org/id/sn"}
+	matZinc        = material{"Zinc", "http://nomisma.org/id/zn"}
+	matIron        = material{"Iron", "http://nomisma.org/id/fe"}
+	matNickel      = material{"Nickel", "http://nomisma.org/id/ni"}
+	matAluminium   = material{"Aluminium", "http://nomisma.org/id/al"}
+	matPlatinum    = material{"Platinum", "http://nomisma.org/id/pt"}
+	matOrichalcum  = material{"Orichalcum", "http://nomisma.org/id/orichalcum"}
+	matPotin       = material{"Potin", "http://nomisma.org/id/potin"}
+)
+
+// Materials keyed by lower-case abbreviation, English name, or name in
+// another language our curators use.
+var materials = map[string]material{
+	// Abbreviations
+	"ar": matSilver,
+	"av": matGold,
+	"au": matGold,
+	"ae": matCopperAlloy,
+	"æ":  matCopperAlloy,
+	"cu": matCopper,
+	"bi": matBillon,
+	"el": matElectrum,
+	"pb": matLead,
+	"sn": matTin,
+	"zn": matZinc,
+	"fe": matIron,
+	"ni": matNickel,
+	"al": matAluminium,
+	"pt": matPlatinum,
+
+	// English
+	"silver":       matSilver,
+	"gold":         matGold,
+	"copper alloy": matCopperAlloy,
+	"copper":       matCopper,
+	"bronze":       matCopperAlloy,
+	"brass":        matBrass,
+	"billon":       matBillon,
+	"electrum":     matElectrum,
+	"lead":         matLead,
+	"tin":          matTin,
+	"zinc":         matZinc,
+	"iron":         matIron,
+	"nickel":       matNickel,
+	"aluminium":    matAluminium,
+	"aluminum":     matAluminium,
+	"platinum":     matPlatinum,
+	"orichalcum":   matOrichalcum,
+	"potin":        matPotin,
+
+	// Russian
+	"серебро":  matSilver,
+	"золото":   matGold,
+	"медь":     matCopper,
+	"бронза":   matCopperAlloy,
+	"латунь":   matBrass,
+	"биллон":   matBillon,
+	"электрум": matElectrum,
+	"свинец":   matLead,
+	"олово":    matTin,
+	"цинк":     matZinc,
+	"железо":   matIron,
+	"никель":   matNickel,
+	"орихалк":  matOrichalcum,
+	"потин":    matPotin,
+
+	// German
+	"silber":   matSilver,
+	"kupfer":   matCopper,
+	"messing":  matBrass,
+	"elektron": matElectrum,
+	"blei":     matLead,
+	"zinn":     matTin,
+	"zink":     matZinc,
+	"eisen":    matIron,
+
+	// French
+	"argent":   matSilver,
+	"or":       matGold,
+	"cuivre":   matCopper,
+	"laiton":   matBrass,
+	"électrum": matElectrum,
+	"plomb":    matLead,
+	"étain":    matTin,
+	"fer":      matIron,
+
+	// Italian and Spanish
+	"argento": matSilver,
+	"oro":     matGold,
+	"rame":    matCopper,
+	"bronzo":  matCopperAlloy,
+	"ottone":  matBrass,
+	"piombo":  matLead,
+	"plata":   matSilver,
+	"cobre":   matCopper,
+	"plomo":   matLead,
+}
+
+var (
+	// "silver washed AE", "silver-plated bronze", "AR plated AE"
+	platedRE = regexp.MustCompile(`^(.+?)[\s-]+(washed|plated|clad)\s+(.+)$`)
+
+	// "Tin-zinc alloy", "copper-tin alloy"
+	alloyRE = regexp.MustCompile(`^(.+?)\s*[-/+]\s*(.+?)\s+alloy$`)
+)
+
+// normalizeMaterial() lower-cases and collapses whitespace
+func normalizeMaterial(val string) string {
+	return strings.Join(strings.Fields(strings.ToLower(val)), " ")
+}
+
+func lookupMaterial(val string) (simplenuds.Material, bool) {
+	m, ok := materials[normalizeMaterial(val)]
+	if !ok {
+		return simplenuds.Material{}, false
+	}
+
+	return simplenuds.Material{
+		HRef: m.HRef,
+		Type: "simple",
+		Text: m.Name,
+	}, true
+}
+
+// getMaterials() resolves a metal column value to one or more Nomisma
+// materials.  Plated and washed coins, such as "silver washed AE", are the
+// core material plus a description of the surface treatment.  Alloys of
+// two named metals, such as "Tin-zinc alloy", are both materials.  If the
+// value cannot be resolved, ok is false.
+func getMaterials(val string) (mats []simplenuds.Material, treatment string, ok bool) {
+	normalized := normalizeMaterial(val)
+
+	if material, ok := lookupMaterial(normalized); ok {
+		return []simplenuds.Material{material}, "", true
+	}
+
+	if m := platedRE.FindStringSubmatch(normalized); m != nil {
+		surface, okSurface := lookupMaterial(m[1])
+		core, okCore := lookupMaterial(m[3])
+
+		if okSurface && okCore {
+			return []simplenuds.Material{core}, strings.ToLower(surface.Text) + " " + m[2], true
+		}
+	}
+
+	if m := alloyRE.FindStringSubmatch(normalized); m != nil {
+		first, okFirst := lookupMaterial(m[1])
+		second, okSecond := lookupMaterial(m[2])
+
+		if okFirst && okSecond {
+			return []simplenuds.Material{first, second}, "", true
+		}
+	}
+
+	return []simplenuds.Material{
+		{
+			Text: strings.TrimSpace(val),
+		},
+	}, "", false
+}
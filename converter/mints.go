@@ -0,0 +1,129 @@
+package converter
+
+import (
+	"strings"
+
+	"github.com/esnible/csv-nuds/simplenuds"
+)
+
+type mint struct {
+	// English name of the mint
+	Name string
+
+	// Nomisma URI, if Nomisma has a concept for this mint
+	HRef string
+}
+
+// Mints keyed by lower-case name or mint signature.  Sasanian coins carry
+// an abbreviated mint signature in Pahlavi on the reverse, and collectors
+// usually record the transliterated abbreviation (e.g. "AY", "GD", "BBA").
+// See Gyselen, "La géographie administrative de l'empire sassanide" and
+// Malek, "The Sasanian King Khusrau II" for the attributions.
+var mints = map[string]mint{
+	// Mint names
+	"ctesiphon":    {Name: "Ctesiphon", HRef: "http://nomisma.org/id/ctesiphon"},
+	"susa":         {Name: "Susa", HRef: "http://nomisma.org/id/susa"},
+	"ecbatana":     {Name: "Ecbatana", HRef: "http://nomisma.org/id/ecbatana"},
+	"hamadan":      {Name: "Ecbatana", HRef: "http://nomisma.org/id/ecbatana"},
+	"merv":         {Name: "Merv", HRef: "http://nomisma.org/id/merv"},
+	"marw":         {Name: "Merv", HRef: "http://nomisma.org/id/merv"},
+	"bishapur":     {Name: "Bishapur"},
+	"darabgird":    {Name: "Darabgird"},
+	"istakhr":      {Name: "Istakhr"},
+	"ray":          {Name: "Ray"},
+	"rey":          {Name: "Ray"},
+	"nihavand":     {Name: "Nihavand"},
+	"hulwan":       {Name: "Hulwan"},
+	"yazd":         {Name: "Yazd"},
+	"shiraz":       {Name: "Shiraz"},
+	"kerman":       {Name: "Kerman"},
+	"sakastan":     {Name: "Sakastan"},
+	"zarang":       {Name: "Zarang"},
+	"abarshahr":    {Name: "Abarshahr"},
+	"nishapur":     {Name: "Abarshahr"},
+	"ahvaz":        {Name: "Ohrmazd-Ardashir"},
+	"jay":          {Name: "Jay"},
+	"gay":          {Name: "Jay"},
+	"veh-ardashir": {Name: "Veh-Ardashir"},
+
+	// Sasanian mint signatures
+	"ahm": {Name: "Ecbatana", HRef: "http://nomisma.org/id/ecbatana"},
+	"apr": {Name: "Abarshahr"},
+	"art": {Name: "Ardashir-Khwarrah"},
+	"at":  {Name: "Adurbadagan"},
+	"aw":  {Name: "Ohrmazd-Ardashir"},
+	"ay":  {Name: "Eran-Khwarrah-Shapur", HRef: "http://nomisma.org/id/susa"},
+	"bba": {Name: "Court mint (BBA)"},
+	"byš": {Name: "Bishapur"},
+	"bys": {Name: "Bishapur"},
+	"da":  {Name: "Darabgird"},
+	"gd":  {Name: "Jay"},
+	"hl":  {Name: "Hulwan"},
+	"kl":  {Name: "Kerman"},
+	"ld":  {Name: "Ray"},
+	"mr":  {Name: "Merv", HRef: "http://nomisma.org/id/merv"},
+	"ny":  {Name: "Nihavand"},
+	"rd":  {Name: "Rew-Ardashir"},
+	"sk":  {Name: "Sakastan"},
+	"st":  {Name: "Istakhr"},
+	"šy":  {Name: "Shiraz"},
+	"sy":  {Name: "Shiraz"},
+	"wh":  {Name: "Veh-Ardashir"},
+	"wyh": {Name: "Veh-Ardashir"},
+	"yz":  {Name: "Yazd"},
+	"zr":  {Name: "Zarang"},
+}
+
+// mintsByName finds mints by their full names, as written by Columns()
+var mintsByName = func() map[string]mint {
+	retval := map[string]mint{}
+	for _, m := range mints {
+		retval[strings.ToLower(m.Name)] = m
+	}
+
+	return retval
+}()
+
+// Values that record that the mint is not known
+var uncertainMints = map[string]bool{
+	"?":         true,
+	"??":        true,
+	"uncertain": true,
+	"unknown":   true,
+	"mint?":     true,
+	"n/a":       true,
+}
+
+// getMint() returns a <geogname> for a mint name or abbreviation.  The
+// boolean is false if the mint could not be resolved.
+func getMint(val string) (simplenuds.Geogname, bool) {
+	key := strings.ToLower(strings.TrimSpace(val))
+
+	if uncertainMints[key] {
+		return simplenuds.Geogname{
+			Role:  "locality",
+			Type:  "simple",
+			Value: "uncertain",
+		}, true
+	}
+
+	m, ok := mints[key]
+	if !ok {
+		m, ok = mintsByName[key]
+	}
+
+	if !ok {
+		return simplenuds.Geogname{
+			Role:  "mint",
+			Type:  "simple",
+			Value: strings.TrimSpace(val),
+		}, false
+	}
+
+	return simplenuds.Geogname{
+		Role:  "mint",
+		Type:  "simple",
+		Href:  m.HRef,
+		Value: m.Name,
+	}, true
+}
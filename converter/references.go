@@ -0,0 +1,237 @@
+package converter
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/esnible/csv-nuds/simplenuds"
+)
+
+type typeSeries struct {
+	// Abbreviation used in citations, e.g. "RRC"
+	Abbreviation string
+
+	// Full title of the catalog
+	Name string
+
+	// Nomisma URI, if Nomisma has a concept for this type series
+	HRef string
+
+	// Prefix of the URIs of types in the online corpus of this series
+	Items string
+
+	// itemID() gives the identifier of a type in the online corpus from
+	// its number in the catalog, if the corpus derives one from the other
+	itemID func(idno string) string
+}
+
+// Type series and other catalogs, keyed by the lower-case abbreviations
+// collectors cite them with.  Citations of an unknown catalog are kept as
+// free text.
+var typeSeriesCatalogs = map[string]*typeSeries{
+	"ric":      seriesRIC,
+	"rrc":      seriesRRC,
+	"crawford": seriesRRC,
+	"price":    seriesPrice,
+	"göbl":     seriesGobl,
+	"gobl":     seriesGobl,
+	"goebl":    seriesGobl,
+	"sns":      seriesSNS,
+}
+
+var (
+	seriesRIC = &typeSeries{
+		Abbreviation: "RIC",
+		Name:         "Roman Imperial Coinage",
+		HRef:         "http://nomisma.org/id/ric",
+		Items:        "http://numismatics.org/ocre/id/",
+	}
+	seriesRRC = &typeSeries{
+		Abbreviation: "RRC",
+		Name:         "Roman Republican Coinage",
+		HRef:         "http://nomisma.org/id/rrc",
+		Items:        "http://numismatics.org/crro/id/",
+		// RRC 44/5 is http://numismatics.org/crro/id/rrc-44.5
+		itemID: func(idno string) string {
+			if !rrcNumberRE.MatchString(idno) {
+				return ""
+			}
+
+			return "rrc-" + strings.ReplaceAll(strings.ToLower(idno), "/", ".")
+		},
+	}
+	seriesPrice = &typeSeries{
+		Abbreviation: "Price",
+		Name:         "The Coinage in the Name of Alexander the Great and Philip Arrhidaeus",
+		Items:        "http://numismatics.org/pella/id/",
+		// Price 3264 is http://numismatics.org/pella/id/price.3264
+		itemID: func(idno string) string {
+			if !priceNumberRE.MatchString(idno) {
+				return ""
+			}
+
+			return "price." + idno
+		},
+	}
+	seriesGobl = &typeSeries{
+		Abbreviation: "Göbl",
+		Name:         "Sasanian Numismatics",
+	}
+	seriesSNS = &typeSeries{
+		Abbreviation: "SNS",
+		Name:         "Sylloge Nummorum Sasanidarum",
+	}
+)
+
+var (
+	rrcNumberRE   = regexp.MustCompile(`^\d+/\d+[a-z]?$`)
+	priceNumberRE = regexp.MustCompile(`^\d+[a-z]?$`)
+	citationRE    = regexp.MustCompile(`^(\S+)\s+(.+)$`)
+)
+
+// referenceHandler() reads citations separated by semicolons, such as
+// "Göbl II/2; SNS 123; RRC 44/5".  A citation of a known catalog is
+// split into a TEI title and number; anything else is free text.  A URL
+// in a citation becomes its xlink:href.  The first citation that names a
+// type in an online corpus also fills <typeSeries> and <typeSeriesItem>,
+//
+//	<refDesc>
+//	  <reference><tei:title>Göbl</tei:title><tei:idno>II/2</tei:idno></reference>
+//	  <reference xlink:type="simple" xlink:href="http://numismatics.org/crro/id/rrc-44.5">
+//	    <tei:title key="http://nomisma.org/id/rrc">RRC</tei:title><tei:idno>44/5</tei:idno>
+//	  </reference>
+//	</refDesc>
+func referenceHandler(coin *simplenuds.NUDS, val string) error {
+	var invalid []string
+
+	for _, citation := range strings.Split(val, ";") {
+		citation = strings.TrimSpace(citation)
+		if citation == "" {
+			continue
+		}
+
+		reference, series, ok := parseCitation(citation)
+		if !ok {
+			invalid = append(invalid, citation)
+		}
+
+		coin.DescMeta.DefaultRefDesc().AppendReference(reference)
+
+		typeDesc := &coin.DescMeta.TypeDesc
+		if series != nil && reference.Href != "" && typeDesc.TypeSeriesItem == nil {
+			typeDesc.TypeSeries = &simplenuds.TypeSeries{
+				Href:  series.HRef,
+				Value: series.Name,
+			}
+
+			// Catalogs such as Price have no Nomisma URI to link to
+			if series.HRef != "" {
+				typeDesc.TypeSeries.Type = "simple"
+			}
+			typeDesc.TypeSeriesItem = &simplenuds.TypeSeriesItem{
+				Type:  "simple",
+				Href:  reference.Href,
+				Value: citationText(reference),
+			}
+		}
+	}
+
+	if len(invalid) > 0 {
+		return warningf(CodeInvalidReference, "not a valid URL in %q; keeping as text", strings.Join(invalid, "; "))
+	}
+
+	return nil
+}
+
+// parseCitation() turns one citation into a <reference>.  The type series
+// is returned if the reference is to a type in a known online corpus.
+// The boolean is false if the citation has a malformed URL.
+func parseCitation(citation string) (simplenuds.Reference, *typeSeries, bool) {
+	var reference simplenuds.Reference
+
+	var words []string
+
+	valid := true
+
+	for _, word := range strings.Fields(citation) {
+		word = strings.Trim(word, "<>")
+
+		if strings.HasPrefix(word, "http://") || strings.HasPrefix(word, "https://") {
+			if _, err := url.ParseRequestURI(word); err != nil {
+				valid = false
+			} else if reference.Href == "" {
+				reference.Href = word
+				continue
+			}
+		}
+
+		words = append(words, word)
+	}
+
+	text := strings.Join(words, " ")
+
+	if match := citationRE.FindStringSubmatch(text); match != nil && typeSeriesCatalogs[strings.ToLower(match[1])] != nil {
+		cited := typeSeriesCatalogs[strings.ToLower(match[1])]
+
+		reference.Title = &simplenuds.TEITitle{Key: cited.HRef, Value: cited.Abbreviation}
+		reference.Idno = &simplenuds.TEIIdno{Value: match[2]}
+
+		if reference.Href == "" && cited.itemID != nil {
+			if id := cited.itemID(match[2]); id != "" {
+				reference.Href = cited.Items + id
+			}
+		}
+	} else {
+		reference.Value = text
+	}
+
+	if reference.Href != "" {
+		reference.Type = "simple"
+	}
+
+	// The series whose online corpus the reference links to
+	var series *typeSeries
+
+	for _, s := range typeSeriesCatalogs {
+		if s.Items != "" && strings.HasPrefix(reference.Href, s.Items) {
+			series = s
+		}
+	}
+
+	return reference, series, valid
+}
+
+// citationText() gives a reference as it would be cited, without its URL
+func citationText(reference simplenuds.Reference) string {
+	if reference.Title != nil && reference.Idno != nil {
+		return reference.Title.Value + " " + reference.Idno.Value
+	}
+
+	return strings.TrimSpace(reference.Value)
+}
+
+// exportReferences() gives the reference column the way referenceHandler() reads it
+func exportReferences(refDesc *simplenuds.RefDesc) []string {
+	if refDesc == nil {
+		return nil
+	}
+
+	var citations []string
+
+	for _, reference := range refDesc.Reference {
+		citation := citationText(reference)
+
+		if reference.Href != "" {
+			citation = strings.TrimSpace(citation + " " + reference.Href)
+		}
+
+		citations = append(citations, nonEmpty(citation)...)
+	}
+
+	if len(citations) == 0 {
+		return nil
+	}
+
+	return []string{strings.Join(citations, "; ")}
+}
@@ -0,0 +1,160 @@
+package converter
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/esnible/csv-nuds/simplenuds"
+)
+
+// Legend lines are separated by line breaks within the cell or by " / ",
+// the way catalogs print them
+var legendLineRE = regexp.MustCompile(`\s*\n\s*|\s+/\s+`)
+
+// A side of the coin, <obverse> or <reverse>
+type sideOf func(typeDesc *simplenuds.TypeDesc) *simplenuds.Side
+
+func obverse(typeDesc *simplenuds.TypeDesc) *simplenuds.Side {
+	return typeDesc.DefaultObverse()
+}
+
+func reverse(typeDesc *simplenuds.TypeDesc) *simplenuds.Side {
+	return typeDesc.DefaultReverse()
+}
+
+// sideTypeHandler() describes what is depicted on a side, e.g.
+//
+//	<obverse>
+//	  <type><description xml:lang="en">Bust of Khusru II right, wearing winged crown</description></type>
+//	</obverse>
+func sideTypeHandler(side sideOf) NUDSWriter {
+	return func(coin *simplenuds.NUDS, val string) error {
+		side(&coin.DescMeta.TypeDesc).DefaultType().AppendDescription(simplenuds.Description{
+			Lang:  "en",
+			Value: val,
+		})
+
+		return nil
+	}
+}
+
+// sideLegendHandler() writes the inscription on a side.  A legend of one
+// line is plain text.  A legend of several lines is a TEI edition with one
+// <tei:ab> per line.  Legends written in a script other than Latin, as
+// opposed to transliterated, get the script in scriptPhysical, e.g.
+//
+//	<reverse>
+//	  <legend scriptPhysical="Inscriptional Pahlavi">...</legend>
+//	</reverse>
+func sideLegendHandler(side sideOf) NUDSWriter {
+	return func(coin *simplenuds.NUDS, val string) error {
+		legend := simplenuds.Legend{
+			ScriptPhysical: scriptOf(val),
+		}
+
+		lines := legendLineRE.Split(val, -1)
+		if len(lines) == 1 {
+			legend.Value = val
+		} else {
+			legend.TEI = &simplenuds.TEIDiv{Type: "edition"}
+
+			for _, line := range lines {
+				if line != "" {
+					legend.TEI.Ab = append(legend.TEI.Ab, simplenuds.TEIAb{Value: line})
+				}
+			}
+		}
+
+		side(&coin.DescMeta.TypeDesc).AppendLegend(legend)
+
+		return nil
+	}
+}
+
+// sidePortraitHandler() names the person portrayed on a side.  Rulers
+// are resolved against the table of rulers; anyone else, such as a
+// deity, is written as given.
+//
+//	<obverse>
+//	  <persname xlink:role="portrait" xlink:type="simple">Khusru II</persname>
+//	</obverse>
+func sidePortraitHandler(side sideOf) NUDSWriter {
+	return func(coin *simplenuds.NUDS, val string) error {
+		persname, _ := rulerPersname(val, "portrait")
+
+		side(&coin.DescMeta.TypeDesc).AppendPersname(persname)
+
+		return nil
+	}
+}
+
+// sideSymbolHandler() records a symbol on a side, such as the star and
+// crescent flanking a Sasanian fire altar
+func sideSymbolHandler(side sideOf) NUDSWriter {
+	return func(coin *simplenuds.NUDS, val string) error {
+		side(&coin.DescMeta.TypeDesc).AppendSymbol(simplenuds.Symbol{
+			Value: val,
+		})
+
+		return nil
+	}
+}
+
+// scriptOf() names the script of the first letter that is not Latin, e.g.
+// "Greek" or "Inscriptional Pahlavi".  It is "" for Latin text, which
+// includes transliterations.
+func scriptOf(val string) string {
+	for _, r := range val {
+		if !unicode.IsLetter(r) || unicode.Is(unicode.Latin, r) {
+			continue
+		}
+
+		for name, table := range unicode.Scripts {
+			if name != "Common" && name != "Inherited" && unicode.Is(table, r) {
+				return strings.ReplaceAll(name, "_", " ")
+			}
+		}
+	}
+
+	return ""
+}
+
+// exportSide() gives the column values of a side the way the side
+// handlers read them
+func exportSide(side *simplenuds.Side) (types, legends, portraits, symbols []string) {
+	if side == nil {
+		return
+	}
+
+	if side.Type != nil {
+		for _, description := range side.Type.Description {
+			types = append(types, nonEmpty(description.Value)...)
+		}
+	}
+
+	for _, legend := range side.Legend {
+		if legend.TEI != nil {
+			var lines []string
+			for _, ab := range legend.TEI.Ab {
+				lines = append(lines, ab.Value)
+			}
+
+			legends = append(legends, strings.Join(lines, " / "))
+		} else {
+			legends = append(legends, nonEmpty(legend.Value)...)
+		}
+	}
+
+	for _, persname := range side.Persname {
+		if persname.Role == "portrait" {
+			portraits = append(portraits, nonEmpty(persname.Value)...)
+		}
+	}
+
+	for _, symbol := range side.Symbol {
+		symbols = append(symbols, nonEmpty(symbol.Value)...)
+	}
+
+	return
+}
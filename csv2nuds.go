@@ -4,32 +4,87 @@ package main
 
 import (
 	"encoding/csv"
-	"encoding/xml"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"os"
-	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/esnible/csv-nuds/converter"
+	"github.com/esnible/csv-nuds/exist"
+	"github.com/esnible/csv-nuds/spreadsheet"
 )
 
 // Convert CSV to NUDS
 // nolint: funlen
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "check" {
+		os.Exit(checkMain(os.Args[2:]))
+	}
+
+	ruler := flag.String("ruler", "", "ruler whose regnal years are used in the date column, e.g. \"Khusru II\"")
+	mappingName := flag.String("mapping", "", "JSON file mapping CSV headers to handlers")
+	checkSchema := flag.Bool("check", false, "check each record against the bundled subset of the NUDS schema before writing it")
+	diagnosticsFormat := flag.String("diagnostics", "text", "how to print diagnostics: \"text\" or \"json\" (JSON lines)")
+	sheet := flag.String("sheet", "", "name or number of the sheet of coins in an .xlsx or .ods file (default the first)")
+	everySheet := flag.String("every-sheet", "", "name or number of the sheet with values for every coin in an .xlsx or .ods file")
+	format := flag.String("format", "nuds", "output format: \"nuds\" (XML), \"turtle\" or \"rdfxml\" (Nomisma RDF), or \"linkedart\" (JSON-LD)")
+	base := flag.String("base", "", "URI prefix for the coins in RDF and Linked Art, followed by the record ID")
+	workers := flag.Int("j", runtime.NumCPU(), "number of records to convert in parallel")
+	publishURL := flag.String("publish", "", "upload records to the eXist-db at this URL, e.g. http://localhost:8888, instead of writing files")
+	collection := flag.String("collection", "collection1", "Numishare collection to -publish to")
+	user := flag.String("user", "admin", "eXist-db user to -publish as; the password is taken from $EXIST_PASSWORD")
+	connections := flag.Int("connections", 4, "number of records to -publish at once")
+	retries := flag.Int("retries", 3, "times to retry a record that could not be published")
+	prune := flag.Bool("prune", false, "remove files in <outputdir> for records that are no longer in the input")
+	imagesDir := flag.String("images", "", "read image columns as paths of files in this directory, recording their size and checksum")
+	mediaDir := flag.String("media", "", "copy the -images of the records into this directory")
+	symlink := flag.Bool("symlink", false, "link rather than copy images into -media")
+	mediaBase := flag.String("media-base", "", "URL prefix of -images, followed by their path within -images (default file: URLs)")
+	flag.Parse()
+
+	args := flag.Args()
+
+	// Without -publish the records are written to a directory
+	inputs := args
+	dirName := ""
+
+	if *publishURL == "" && len(args) > 0 {
+		dirName, inputs = args[0], args[1:]
+	}
+
+	if len(inputs) < 1 || len(inputs) > 2 {
+		fmt.Fprintf(os.Stderr, "syntax: %s [-ruler <name>] [-mapping <json>] [-check] [-diagnostics text|json] [-format nuds|turtle|rdfxml|linkedart] [-base <uri>] [-j <n>] [-sheet <sheet>] [-every-sheet <sheet>] [-images <dir> [-media <dir>] [-symlink] [-media-base <url>]] <outputdir> <csv, xlsx or ods> [<csv, xlsx or ods>]\n"+
+			"        %s -publish <url> [-collection <name>] [-user <name>] [-connections <n>] [-retries <n>] [...] <csv, xlsx or ods> [<csv, xlsx or ods>]\n"+
+			"        %s check <nuds.xml or dir>...\n", os.Args[0], os.Args[0], os.Args[0])
+		os.Exit(3)
+	}
 
-	if len(os.Args) < 3 || len(os.Args) > 4 {
-		fmt.Fprintf(os.Stderr, "syntax: %s <outputdir> <csvname> [<csvname>]\n", os.Args[0])
+	csvName := inputs[0]
+
+	if *publishURL != "" && *format != "nuds" {
+		fmt.Fprintln(os.Stderr, "-publish uploads NUDS; it cannot be used with -format")
+		os.Exit(3)
+	}
+
+	output, err := newOutputFormat(*format, *base)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
 		os.Exit(3)
 	}
 
-	dirName := os.Args[1]
-	csvName := os.Args[2]
-	csvEveryName := os.Args[3]
+	diagnosticsOut, err := newDiagnosticsWriter(os.Stderr, *diagnosticsFormat)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(3)
+	}
 
-	// We will generate one record for every row in the .CSV
-	csvCoinReader, cols, err := csvReader(csvName)
+	// We will generate one record for every row in the .CSV or sheet
+	csvCoinReader, cols, err := openRecords(csvName, *sheet)
 	if err != nil {
 		fmt.Println(err.Error())
 		os.Exit(1)
@@ -39,15 +94,25 @@ func main() {
 	// row.  The row's values will be applied to every generated coin.
 	// It is for applying stuff that should appear on every record,
 	// such as the owner, copyright, database export timestamp, etc.
+	// A workbook can instead keep that row on another sheet.
 
 	var colsEveryCoin map[int]string
 
 	var recEveryCoin []string
 
-	if len(os.Args) == 4 {
-		var csvEveryCoinReader *csv.Reader
+	everyName := ""
+
+	switch {
+	case len(inputs) == 2:
+		everyName = inputs[1]
+	case *everySheet != "":
+		everyName = csvName
+	}
+
+	if everyName != "" {
+		var csvEveryCoinReader records
 
-		csvEveryCoinReader, colsEveryCoin, err = csvReader(csvEveryName)
+		csvEveryCoinReader, colsEveryCoin, err = openRecords(everyName, *everySheet)
 		if err != nil {
 			fmt.Println(err.Error())
 			os.Exit(1)
@@ -60,45 +125,210 @@ func main() {
 		}
 	}
 
-	converter := converter.NewConverter(time.Now())
+	var localImages *converter.LocalImages
 
-	// Go through each row in the CSV, producing a <NUDS> for each
-	for {
-		rec, err := csvCoinReader.Read()
-		if err == io.EOF {
-			break
+	if *imagesDir != "" {
+		localImages = &converter.LocalImages{
+			Dir:      *imagesDir,
+			MediaDir: *mediaDir,
+			Symlink:  *symlink,
+			BaseURL:  *mediaBase,
 		}
+	} else if *mediaDir != "" || *symlink || *mediaBase != "" {
+		fmt.Fprintln(os.Stderr, "-media, -symlink and -media-base need -images")
+		os.Exit(3)
+	}
 
+	mapping := &converter.Mapping{}
+	if *mappingName != "" {
+		mapping, err = converter.ReadMappingFile(*mappingName)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err.Error())
-			os.Exit(1)
+			os.Exit(3)
 		}
+	}
+
+	converter, err := converter.NewConverterWithOptions(time.Now(), converter.Options{
+		Mapping:     mapping,
+		RegnalRuler: *ruler,
+		LocalImages: localImages,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(3)
+	}
 
-		coin := generateMap(cols, rec, colsEveryCoin, recEveryCoin)
+	converter.CheckSchema = *checkSchema
 
-		nuds, err := converter.GenerateNUDS(coin)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, err.Error())
-			os.Exit(1)
+	invalid := false
+
+	p := &pipeline{
+		conv:    &converter,
+		encode:  output.encode,
+		workers: *workers,
+	}
+
+	// Either publish, or write only what changed since the last run
+	var publisher *exist.Publisher
+
+	var od *outputDir
+
+	if *publishURL != "" {
+		publisher = newPublisher(*publishURL, *collection, *user, *connections, *retries)
+	} else {
+		od = newOutputDir(dirName, output.ext, *format == "nuds", converter.Timestamp)
+		p.previous = od
+	}
+
+	// Rows that could not be read have no record ID, so we can't tell
+	// whether their records were deleted
+	unreadable := false
+
+	rr := &rowReader{
+		reader:    csvCoinReader,
+		cols:      cols,
+		everyCols: colsEveryCoin,
+		every:     recEveryCoin,
+	}
+
+	// Convert each row in the CSV to a <NUDS>.  Results arrive in the
+	// order of the CSV, so problems are reported by ascending line.
+	report, err := p.run(rr.next, func(res result) error {
+		if res.err != nil {
+			fmt.Fprintf(os.Stderr, "%s:%d: %s\n", csvName, res.line, res.err)
+
+			invalid = true
+			unreadable = unreadable || res.recordID == ""
+
+			if od != nil {
+				od.fail(res.recordID)
+			}
+
+			return nil
 		}
 
-		fXML, err := os.Create(filepath.Join(dirName, nuds.Control.RecordID+".xml"))
-		if err != nil {
-			fmt.Fprintln(os.Stderr, err.Error())
-			os.Exit(1)
+		diagnosticsOut.write(res.diagnostics)
+
+		// Don't write records that failed the schema check
+		if res.data == nil && res.status != statusUnchanged {
+			invalid = true
+
+			if od != nil {
+				od.fail(res.recordID)
+			}
+
+			return nil
 		}
 
-		encoder := xml.NewEncoder(fXML)
-		encoder.Indent(" ", "  ")
+		if publisher != nil {
+			publisher.Publish(res.recordID, res.data)
+			return nil
+		}
 
-		err = encoder.Encode(nuds)
-		if err != nil {
+		return od.write(res)
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+
+	if publisher != nil && !printSummary(os.Stderr, publisher.Wait()) {
+		invalid = true
+	}
+
+	if od != nil {
+		if unreadable {
+			fmt.Fprintln(os.Stderr, "some rows could not be read; not looking for deleted records")
+		} else if err := od.findDeleted(*prune); err != nil {
 			fmt.Fprintln(os.Stderr, err.Error())
 			os.Exit(1)
 		}
+
+		od.printSummary(os.Stdout)
+	}
+
+	diagnosticsOut.summarize(report)
+
+	if invalid {
+		os.Exit(1)
 	}
 }
 
+// diagnosticsWriter prints converter diagnostics as text or JSON lines
+type diagnosticsWriter struct {
+	w       io.Writer
+	encoder *json.Encoder
+}
+
+func newDiagnosticsWriter(w io.Writer, format string) (*diagnosticsWriter, error) {
+	switch format {
+	case "text":
+		return &diagnosticsWriter{w: w}, nil
+	case "json":
+		return &diagnosticsWriter{w: w, encoder: json.NewEncoder(w)}, nil
+	}
+
+	return nil, fmt.Errorf("unknown diagnostics format %q", format)
+}
+
+// write() prints a record's diagnostics.  As text, informational
+// diagnostics are left to summarize().
+func (dw *diagnosticsWriter) write(diagnostics converter.Diagnostics) {
+	for _, diagnostic := range diagnostics {
+		if dw.encoder != nil {
+			_ = dw.encoder.Encode(diagnostic)
+			continue
+		}
+
+		if diagnostic.Severity != converter.SeverityInfo {
+			fmt.Fprintln(dw.w, diagnostic)
+		}
+	}
+}
+
+// summarize() prints the columns that had no handler, once each
+func (dw *diagnosticsWriter) summarize(report *converter.Report) {
+	if dw.encoder != nil {
+		return
+	}
+
+	unknown := report.UnknownColumns()
+
+	columns := make([]string, 0, len(unknown))
+	for column := range unknown {
+		columns = append(columns, column)
+	}
+
+	sort.Strings(columns)
+
+	for _, column := range columns {
+		fmt.Fprintf(dw.w, "no handler for %q in %d records; ignoring\n", column, unknown[column])
+	}
+}
+
+// openRecords() reads the header of a CSV file or a sheet of a workbook
+func openRecords(fileName, sheet string) (records, map[int]string, error) {
+	if !spreadsheet.IsWorkbook(fileName) {
+		if sheet != "" {
+			return nil, nil, fmt.Errorf("%s: only .xlsx and .ods files have sheets", fileName)
+		}
+
+		return csvReader(fileName)
+	}
+
+	reader, err := spreadsheet.Open(fileName, sheet)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: no header row: %w", fileName, err)
+	}
+
+	return reader, generateColumnLookup(header), nil
+}
+
 // csvReader() opens a .csv file, returning a reader and a column-to-header lookup
 func csvReader(fileName string) (*csv.Reader, map[int]string, error) {
 	fCSV, err := os.Open(fileName)
@@ -0,0 +1,232 @@
+// Package exist uploads NUDS records to the REST API of an eXist-db
+// database, such as the one behind a Numishare server.
+// See https://exist-db.org/exist/apps/doc/devguide_rest
+package exist
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Client puts records into a Numishare collection,
+// <BaseURL>/exist/rest/db/<Collection>/objects/<recordId>.xml
+type Client struct {
+	// Where eXist-db is running, e.g. http://localhost:8888
+	BaseURL string
+
+	// Numishare collection, e.g. collection1
+	Collection string
+
+	// Basic authentication, if User is set
+	User     string
+	Password string
+
+	// Defaults to http.DefaultClient
+	HTTPClient *http.Client
+
+	// How many times to retry a failed upload
+	Retries int
+
+	// Wait before the first retry; it doubles for each retry after that
+	Backoff time.Duration
+}
+
+// StatusError is an unsuccessful response from eXist-db
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Status     string
+
+	// The start of the response, which often explains the problem
+	Body string
+}
+
+func (err *StatusError) Error() string {
+	if err.Body == "" {
+		return fmt.Sprintf("PUT %s: %s", err.URL, err.Status)
+	}
+
+	return fmt.Sprintf("PUT %s: %s: %s", err.URL, err.Status, err.Body)
+}
+
+// Temporary() is true for responses worth retrying, such as 503 Service Unavailable
+func (err *StatusError) Temporary() bool {
+	return err.StatusCode >= 500 || err.StatusCode == http.StatusTooManyRequests ||
+		err.StatusCode == http.StatusRequestTimeout
+}
+
+// RecordURL() is where a record is stored
+func (client *Client) RecordURL(recordID string) string {
+	return strings.TrimSuffix(client.BaseURL, "/") + "/exist/rest/db/" +
+		strings.Trim(client.Collection, "/") + "/objects/" + url.PathEscape(recordID) + ".xml"
+}
+
+// Put() stores a record, replacing any earlier version.  Network errors
+// and temporary failures are retried; other failures, such as a wrong
+// password, are not.
+func (client *Client) Put(ctx context.Context, recordID string, data []byte) error {
+	backoff := client.Backoff
+
+	for attempt := 0; ; attempt++ {
+		err := client.put(ctx, recordID, data)
+		if err == nil {
+			return nil
+		}
+
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && !statusErr.Temporary() {
+			return err
+		}
+
+		if attempt >= client.Retries || ctx.Err() != nil {
+			return err
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return err
+		}
+
+		backoff *= 2
+	}
+}
+
+func (client *Client) put(ctx context.Context, recordID string, data []byte) error {
+	recordURL := client.RecordURL(recordID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, recordURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/xml")
+
+	if client.User != "" {
+		req.SetBasicAuth(client.User, client.Password)
+	}
+
+	httpClient := client.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	// eXist-db answers 201 Created, even when replacing a document
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	return &StatusError{
+		URL:        recordURL,
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Body:       strings.TrimSpace(string(body)),
+	}
+}
+
+// A Failure is a record that could not be published
+type Failure struct {
+	RecordID string
+	Err      error
+}
+
+// Summary of a run of a Publisher
+type Summary struct {
+	Published int
+
+	// In the order the records were given to Publish()
+	Failures []Failure
+}
+
+// Publisher uploads records in the background, a limited number at a time
+type Publisher struct {
+	client *Client
+	ctx    context.Context
+
+	// One token per upload in progress
+	slots chan struct{}
+	wg    sync.WaitGroup
+
+	mu        sync.Mutex
+	published int
+	failures  map[int]Failure
+	count     int
+}
+
+// NewPublisher() uploads with client, at most connections at a time
+func NewPublisher(ctx context.Context, client *Client, connections int) *Publisher {
+	if connections < 1 {
+		connections = 1
+	}
+
+	return &Publisher{
+		client:   client,
+		ctx:      ctx,
+		slots:    make(chan struct{}, connections),
+		failures: map[int]Failure{},
+	}
+}
+
+// Publish() starts uploading a record.  It waits while the maximum number
+// of uploads are in progress.  It must not be called after Wait().
+func (publisher *Publisher) Publish(recordID string, data []byte) {
+	publisher.mu.Lock()
+	seq := publisher.count
+	publisher.count++
+	publisher.mu.Unlock()
+
+	publisher.slots <- struct{}{}
+	publisher.wg.Add(1)
+
+	go func() {
+		defer func() {
+			<-publisher.slots
+			publisher.wg.Done()
+		}()
+
+		err := publisher.client.Put(publisher.ctx, recordID, data)
+
+		publisher.mu.Lock()
+		defer publisher.mu.Unlock()
+
+		if err != nil {
+			publisher.failures[seq] = Failure{RecordID: recordID, Err: err}
+		} else {
+			publisher.published++
+		}
+	}()
+}
+
+// Wait() waits for every upload to finish and summarizes them
+func (publisher *Publisher) Wait() Summary {
+	publisher.wg.Wait()
+
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	summary := Summary{Published: publisher.published}
+
+	for seq := 0; seq < publisher.count; seq++ {
+		if failure, ok := publisher.failures[seq]; ok {
+			summary.Failures = append(summary.Failures, failure)
+		}
+	}
+
+	return summary
+}
@@ -0,0 +1,179 @@
+package exist
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeExist stands in for the eXist-db REST API
+type fakeExist struct {
+	mu        sync.Mutex
+	documents map[string]string
+	attempts  map[string]int
+
+	// Fail this many times with 503 before storing a document
+	unavailable int
+
+	// Records that are always refused
+	forbidden map[string]bool
+
+	inFlight    int32
+	maxInFlight int32
+}
+
+func newFakeExist() *fakeExist {
+	return &fakeExist{
+		documents: map[string]string{},
+		attempts:  map[string]int{},
+		forbidden: map[string]bool{},
+	}
+}
+
+func (fake *fakeExist) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	n := atomic.AddInt32(&fake.inFlight, 1)
+	defer atomic.AddInt32(&fake.inFlight, -1)
+
+	for {
+		max := atomic.LoadInt32(&fake.maxInFlight)
+		if n <= max || atomic.CompareAndSwapInt32(&fake.maxInFlight, max, n) {
+			break
+		}
+	}
+
+	// Give other uploads a chance to overlap
+	time.Sleep(2 * time.Millisecond)
+
+	user, password, ok := req.BasicAuth()
+	if !ok || user != "admin" || password != "secret" {
+		http.Error(w, "Wrong password", http.StatusUnauthorized)
+		return
+	}
+
+	if req.Method != http.MethodPut || !strings.HasPrefix(req.URL.Path, "/exist/rest/db/collection1/objects/") {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	if req.Header.Get("Content-Type") != "application/xml" {
+		http.Error(w, "Bad content type", http.StatusBadRequest)
+		return
+	}
+
+	body, _ := io.ReadAll(req.Body)
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	fake.attempts[req.URL.Path]++
+
+	if fake.forbidden[req.URL.Path] {
+		http.Error(w, "Permission denied", http.StatusForbidden)
+		return
+	}
+
+	if fake.attempts[req.URL.Path] <= fake.unavailable {
+		http.Error(w, "Try later", http.StatusServiceUnavailable)
+		return
+	}
+
+	fake.documents[req.URL.Path] = string(body)
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+func newTestClient(url string) *Client {
+	return &Client{
+		BaseURL:    url,
+		Collection: "collection1",
+		User:       "admin",
+		Password:   "secret",
+		Retries:    3,
+		Backoff:    time.Millisecond,
+	}
+}
+
+func TestPut(t *testing.T) {
+	fake := newFakeExist()
+	fake.unavailable = 2
+
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+
+	err := client.Put(context.Background(), "264199", []byte("<nuds/>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := "/exist/rest/db/collection1/objects/264199.xml"
+	if fake.documents[path] != "<nuds/>" || fake.attempts[path] != 3 {
+		t.Errorf("got %q after %d attempts", fake.documents[path], fake.attempts[path])
+	}
+
+	// Out of retries
+	fake.unavailable = 10
+
+	err = client.Put(context.Background(), "58627", []byte("<nuds/>"))
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("got %v, expected 503", err)
+	}
+
+	if attempts := fake.attempts["/exist/rest/db/collection1/objects/58627.xml"]; attempts != 4 {
+		t.Errorf("got %d attempts, expected 4", attempts)
+	}
+
+	// A wrong password is not retried
+	client.Password = "wrong"
+
+	err = client.Put(context.Background(), "90886", []byte("<nuds/>"))
+	if err == nil || !strings.Contains(err.Error(), "401") || !strings.Contains(err.Error(), "Wrong password") {
+		t.Errorf("got %v, expected 401", err)
+	}
+}
+
+func TestPublisher(t *testing.T) {
+	fake := newFakeExist()
+	fake.forbidden["/exist/rest/db/collection1/objects/3.xml"] = true
+	fake.forbidden["/exist/rest/db/collection1/objects/7.xml"] = true
+
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	publisher := NewPublisher(context.Background(), newTestClient(server.URL), 3)
+
+	ids := []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9"}
+	for _, id := range ids {
+		publisher.Publish(id, []byte("<nuds><control><recordId>"+id+"</recordId></control></nuds>"))
+	}
+
+	summary := publisher.Wait()
+
+	if summary.Published != 8 || len(fake.documents) != 8 {
+		t.Errorf("published %d of 10 records, stored %d", summary.Published, len(fake.documents))
+	}
+
+	if len(summary.Failures) != 2 || summary.Failures[0].RecordID != "3" || summary.Failures[1].RecordID != "7" {
+		t.Errorf("got failures %v, expected 3 and 7", summary.Failures)
+	}
+
+	if fake.maxInFlight > 3 {
+		t.Errorf("%d uploads at once, expected at most 3", fake.maxInFlight)
+	}
+}
+
+func TestRecordURL(t *testing.T) {
+	client := &Client{BaseURL: "http://localhost:8888/", Collection: "/collection1/"}
+
+	got := client.RecordURL("1922.999.73 a")
+	if expected := "http://localhost:8888/exist/rest/db/collection1/objects/1922.999.73%20a.xml"; got != expected {
+		t.Errorf("got %s, expected %s", got, expected)
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/esnible/csv-nuds/simplenuds"
+)
+
+// How a record compares with the file from the previous run
+type recordStatus string
+
+const (
+	statusCreated   recordStatus = "created"
+	statusUpdated   recordStatus = "updated"
+	statusUnchanged recordStatus = "unchanged"
+	statusDeleted   recordStatus = "deleted"
+
+	// Not written because of an error; the previous file is left alone
+	statusFailed recordStatus = "failed"
+)
+
+// outputDir is a directory of records from a previous run.  Unchanged
+// records are not written again, so their files keep their timestamps.
+type outputDir struct {
+	dir string
+	ext string
+
+	// If true the files are NUDS, compared ignoring the conversion time
+	nuds bool
+
+	// Time of the "revised" events
+	timestamp time.Time
+
+	// Record IDs by status; only used by the goroutine emitting results
+	ids map[recordStatus][]string
+}
+
+func newOutputDir(dir, ext string, nuds bool, timestamp time.Time) *outputDir {
+	return &outputDir{
+		dir:       dir,
+		ext:       ext,
+		nuds:      nuds,
+		timestamp: timestamp,
+		ids:       map[recordStatus][]string{},
+	}
+}
+
+func (od *outputDir) path(recordID string) string {
+	return filepath.Join(od.dir, recordID+od.ext)
+}
+
+// encode() encodes a record and compares it with the previous run.  A
+// changed NUDS record becomes a revision of the previous one.  Unchanged
+// records are not encoded.
+func (od *outputDir) encode(nuds *simplenuds.NUDS,
+	encode func(*simplenuds.NUDS) ([]byte, error)) ([]byte, recordStatus, error) {
+
+	previous, err := os.ReadFile(od.path(nuds.Control.RecordID))
+	if errors.Is(err, fs.ErrNotExist) {
+		data, err := encode(nuds)
+		return data, statusCreated, err
+	}
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	if !od.nuds {
+		data, err := encode(nuds)
+		if err == nil && bytes.Equal(data, previous) {
+			return nil, statusUnchanged, nil
+		}
+
+		return data, statusUpdated, err
+	}
+
+	previousNUDS, err := simplenuds.Parse(bytes.NewReader(previous))
+	if err != nil {
+		return nil, "", fmt.Errorf("reading the previous %s: %w", od.path(nuds.Control.RecordID), err)
+	}
+
+	if simplenuds.SameContent(nuds, previousNUDS) {
+		return nil, statusUnchanged, nil
+	}
+
+	nuds.Revise(previousNUDS, od.timestamp)
+
+	data, err := encode(nuds)
+
+	return data, statusUpdated, err
+}
+
+// fail() notes a record that was not written
+func (od *outputDir) fail(recordID string) {
+	if recordID != "" {
+		od.ids[statusFailed] = append(od.ids[statusFailed], recordID)
+	}
+}
+
+// write() writes a record unless it is unchanged
+func (od *outputDir) write(res result) error {
+	od.ids[res.status] = append(od.ids[res.status], res.recordID)
+
+	if res.status == statusUnchanged {
+		return nil
+	}
+
+	return os.WriteFile(od.path(res.recordID), res.data, 0o644)
+}
+
+// findDeleted() lists the records in the directory that this run did not
+// convert, removing their files if prune is true
+func (od *outputDir) findDeleted(prune bool) error {
+	seen := map[string]bool{}
+
+	for _, ids := range od.ids {
+		for _, id := range ids {
+			seen[id] = true
+		}
+	}
+
+	names, err := filepath.Glob(filepath.Join(od.dir, "*"+od.ext))
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		recordID := strings.TrimSuffix(filepath.Base(name), od.ext)
+		if seen[recordID] {
+			continue
+		}
+
+		od.ids[statusDeleted] = append(od.ids[statusDeleted], recordID)
+
+		if prune {
+			if err := os.Remove(name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// printSummary() lists the records by status
+func (od *outputDir) printSummary(w io.Writer) {
+	for _, status := range []recordStatus{statusCreated, statusUpdated, statusUnchanged, statusDeleted, statusFailed} {
+		ids := od.ids[status]
+		if status == statusFailed && len(ids) == 0 {
+			continue
+		}
+
+		sort.Strings(ids)
+
+		fmt.Fprintf(w, "%s %d", status, len(ids))
+
+		if len(ids) > 0 {
+			fmt.Fprintf(w, ": %s", strings.Join(ids, ", "))
+		}
+
+		fmt.Fprintln(w)
+	}
+}
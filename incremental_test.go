@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/esnible/csv-nuds/converter"
+)
+
+// runIncremental() converts coins into dir as csv2nuds does, and returns the summary
+func runIncremental(t *testing.T, dir string, timestamp time.Time, coins []map[string]string, prune bool) string {
+	t.Helper()
+
+	conv := converter.NewConverter(timestamp)
+	od := newOutputDir(dir, ".xml", true, timestamp)
+	p := &pipeline{conv: &conv, encode: encodeNUDS, previous: od, workers: 2}
+
+	i := 0
+	next := func() (row, error) {
+		if i >= len(coins) {
+			return row{}, io.EOF
+		}
+
+		i++
+
+		return row{line: i + 1, coin: coins[i-1]}, nil
+	}
+
+	_, err := p.run(next, func(res result) error {
+		if res.err != nil {
+			return res.err
+		}
+
+		return od.write(res)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = od.findDeleted(prune); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+
+	od.printSummary(&buf)
+
+	return buf.String()
+}
+
+func TestIncremental(t *testing.T) {
+	dir := t.TempDir()
+
+	coin := func(id, weight string) map[string]string {
+		return map[string]string{"id": id, "title": "Khusru II AR drachm", "weight": weight}
+	}
+
+	first := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	got := runIncremental(t, dir, first, []map[string]string{coin("1", "3.7"), coin("2", "4.1"), coin("3", "3.9")}, false)
+	if expected := "created 3: 1, 2, 3\nupdated 0\nunchanged 0\ndeleted 0\n"; got != expected {
+		t.Errorf("first run: got\n%s\nexpected\n%s", got, expected)
+	}
+
+	unchanged, err := os.ReadFile(filepath.Join(dir, "1.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Coin 2 changes weight and coin 3 is no longer in the input
+	second := first.Add(24 * time.Hour)
+
+	got = runIncremental(t, dir, second, []map[string]string{coin("1", "3.7"), coin("2", "4.2")}, false)
+	if expected := "created 0\nupdated 1: 2\nunchanged 1: 1\ndeleted 1: 3\n"; got != expected {
+		t.Errorf("second run: got\n%s\nexpected\n%s", got, expected)
+	}
+
+	if data, _ := os.ReadFile(filepath.Join(dir, "1.xml")); !bytes.Equal(data, unchanged) {
+		t.Errorf("unchanged record was rewritten")
+	}
+
+	revised, err := os.ReadFile(filepath.Join(dir, "2.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"<maintenanceStatus>revised</maintenanceStatus>",
+		`<eventDateTime standardDateTime="2021-03-01T12:00:00Z">`,
+		"<eventType>revised</eventType>",
+		`<eventDateTime standardDateTime="2021-03-02T12:00:00Z">`,
+	} {
+		if !bytes.Contains(revised, []byte(expected)) {
+			t.Errorf("revised record has no %s:\n%s", expected, revised)
+		}
+	}
+
+	// Without -prune the deleted record's file stays
+	if _, err := os.Stat(filepath.Join(dir, "3.xml")); err != nil {
+		t.Errorf("deleted record's file was removed without prune: %v", err)
+	}
+
+	got = runIncremental(t, dir, second.Add(time.Hour), []map[string]string{coin("1", "3.7"), coin("2", "4.2")}, true)
+	if expected := "created 0\nupdated 0\nunchanged 2: 1, 2\ndeleted 1: 3\n"; got != expected {
+		t.Errorf("third run: got\n%s\nexpected\n%s", got, expected)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "3.xml")); !os.IsNotExist(err) {
+		t.Errorf("deleted record's file was not pruned: %v", err)
+	}
+}
@@ -0,0 +1,322 @@
+// Package linkedart describes NUDS records as Linked Art JSON-LD,
+// https://linked.art/model/object/
+package linkedart
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/esnible/csv-nuds/simplenuds"
+)
+
+// Context is the JSON-LD context of every Linked Art document
+const Context = "https://linked.art/ns/v1/linked-art.json"
+
+// An Entity is any Linked Art resource: an object, a name, a dimension,
+// a production event, etc.  Only the fields for its type are set.
+type Entity struct {
+	Context string `json:"@context,omitempty"`
+	ID      string `json:"id,omitempty"`
+	Type    string `json:"type"`
+	Label   string `json:"_label,omitempty"`
+
+	ClassifiedAs []*Entity `json:"classified_as,omitempty"`
+	IdentifiedBy []*Entity `json:"identified_by,omitempty"`
+	ReferredToBy []*Entity `json:"referred_to_by,omitempty"`
+
+	// Names, identifiers and statements
+	Content string `json:"content,omitempty"`
+
+	// Dimensions
+	Value *float64 `json:"value,omitempty"`
+	Unit  *Entity  `json:"unit,omitempty"`
+
+	// Objects
+	Dimension      []*Entity `json:"dimension,omitempty"`
+	MadeOf         []*Entity `json:"made_of,omitempty"`
+	ProducedBy     *Entity   `json:"produced_by,omitempty"`
+	Representation []*Entity `json:"representation,omitempty"`
+
+	// Production
+	TookPlaceAt  []*Entity `json:"took_place_at,omitempty"`
+	CarriedOutBy []*Entity `json:"carried_out_by,omitempty"`
+	Timespan     *Entity   `json:"timespan,omitempty"`
+
+	// Time spans
+	BeginOfTheBegin string `json:"begin_of_the_begin,omitempty"`
+	EndOfTheEnd     string `json:"end_of_the_end,omitempty"`
+
+	// Images
+	DigitallyShownBy []*Entity `json:"digitally_shown_by,omitempty"`
+	AccessPoint      []*Entity `json:"access_point,omitempty"`
+}
+
+// Getty AAT concepts used by Linked Art, http://vocab.getty.edu/aat/
+var (
+	aatCoin        = Entity{ID: "http://vocab.getty.edu/aat/300037222", Type: "Type", Label: "coins (money)"}
+	aatPrimaryName = Entity{ID: "http://vocab.getty.edu/aat/300404670", Type: "Type", Label: "preferred terms"}
+	aatDescription = Entity{ID: "http://vocab.getty.edu/aat/300080091", Type: "Type", Label: "description"}
+	aatNote        = Entity{ID: "http://vocab.getty.edu/aat/300027200", Type: "Type", Label: "notes"}
+	aatAccession   = Entity{ID: "http://vocab.getty.edu/aat/300312355", Type: "Type", Label: "accession numbers"}
+	aatBriefText   = Entity{ID: "http://vocab.getty.edu/aat/300418049", Type: "Type", Label: "brief text"}
+	aatWeight      = Entity{ID: "http://vocab.getty.edu/aat/300056240", Type: "Type", Label: "weight"}
+	aatDiameter    = Entity{ID: "http://vocab.getty.edu/aat/300055624", Type: "Type", Label: "diameter"}
+	aatObverse     = Entity{ID: "http://vocab.getty.edu/aat/300190703", Type: "Type", Label: "obverse"}
+	aatReverse     = Entity{ID: "http://vocab.getty.edu/aat/300190692", Type: "Type", Label: "reverse"}
+)
+
+// Units of measurement, by the NUDS @units
+var units = map[string]Entity{
+	"g":  {ID: "http://vocab.getty.edu/aat/300379225", Type: "MeasurementUnit", Label: "grams"},
+	"mm": {ID: "http://vocab.getty.edu/aat/300379097", Type: "MeasurementUnit", Label: "millimeters"},
+	"cm": {ID: "http://vocab.getty.edu/aat/300379098", Type: "MeasurementUnit", Label: "centimeters"},
+}
+
+// gYear as written by simplenuds, e.g. "0591" or "-0100"
+var gYearRE = regexp.MustCompile(`^-?[0-9]{4,}$`)
+
+// Describe() gives a HumanMadeObject for a physical NUDS record.  The
+// object's id is the record ID appended to base.  Materials,
+// denominations and mints are linked by their Nomisma URIs.
+func Describe(nuds *simplenuds.NUDS, base string) *Entity {
+	descMeta := &nuds.DescMeta
+	typeDesc := &descMeta.TypeDesc
+
+	coin := &Entity{
+		Context:      Context,
+		ID:           base + url.PathEscape(nuds.Control.RecordID),
+		Type:         "HumanMadeObject",
+		ClassifiedAs: []*Entity{ref(aatCoin)},
+	}
+
+	for _, title := range descMeta.Title {
+		if title.Value == "" {
+			continue
+		}
+
+		if coin.Label == "" {
+			coin.Label = title.Value
+		}
+
+		coin.IdentifiedBy = append(coin.IdentifiedBy, &Entity{
+			Type:         "Name",
+			ClassifiedAs: []*Entity{ref(aatPrimaryName)},
+			Content:      title.Value,
+		})
+	}
+
+	coin.IdentifiedBy = append(coin.IdentifiedBy, &Entity{
+		Type:    "Identifier",
+		Content: nuds.Control.RecordID,
+	})
+
+	if adminDesc := descMeta.AdminDesc; adminDesc != nil && adminDesc.Identifier != "" {
+		coin.IdentifiedBy = append(coin.IdentifiedBy, &Entity{
+			Type:         "Identifier",
+			ClassifiedAs: []*Entity{ref(aatAccession)},
+			Content:      adminDesc.Identifier,
+		})
+	}
+
+	for _, denomination := range typeDesc.Denomination {
+		if denomination.HRef != "" {
+			coin.ClassifiedAs = append(coin.ClassifiedAs, &Entity{ID: denomination.HRef, Type: "Type", Label: denomination.Value})
+		}
+	}
+
+	if typeDesc.HRef != "" {
+		coin.ClassifiedAs = append(coin.ClassifiedAs, &Entity{ID: typeDesc.HRef, Type: "Type"})
+	}
+
+	if item := typeDesc.TypeSeriesItem; item != nil && item.Href != "" && item.Href != typeDesc.HRef {
+		coin.ClassifiedAs = append(coin.ClassifiedAs, &Entity{ID: item.Href, Type: "Type", Label: item.Value})
+	}
+
+	for _, descriptionSet := range descMeta.DescriptionSet {
+		for _, description := range descriptionSet.Description {
+			coin.ReferredToBy = append(coin.ReferredToBy, statement(description.Value, aatDescription))
+		}
+	}
+
+	for _, noteSet := range descMeta.NoteSet {
+		for _, note := range noteSet.Note {
+			coin.ReferredToBy = append(coin.ReferredToBy, statement(note.Value, aatNote))
+		}
+	}
+
+	if physDesc := descMeta.PhysDesc; physDesc != nil && physDesc.MeasurementsSet != nil {
+		measurements := physDesc.MeasurementsSet
+
+		if measurements.Weight != nil {
+			coin.addDimension(aatWeight, measurements.Weight.Value, measurements.Weight.Units, "g")
+		}
+
+		if measurements.Diameter != nil {
+			coin.addDimension(aatDiameter, measurements.Diameter.Value, measurements.Diameter.Units, "mm")
+		}
+	}
+
+	for _, material := range typeDesc.Material {
+		if material.HRef != "" {
+			coin.MadeOf = append(coin.MadeOf, &Entity{ID: material.HRef, Type: "Material", Label: material.Text})
+		}
+	}
+
+	coin.ProducedBy = production(typeDesc)
+
+	if nuds.DigRep != nil {
+		coin.Representation = representations(nuds.DigRep)
+	}
+
+	return coin
+}
+
+// ref() copies a shared entity, so callers may change what they are given
+func ref(entity Entity) *Entity {
+	return &entity
+}
+
+func statement(content string, classification Entity) *Entity {
+	return &Entity{
+		Type:         "LinguisticObject",
+		ClassifiedAs: []*Entity{ref(classification), ref(aatBriefText)},
+		Content:      content,
+	}
+}
+
+// addDimension() adds a measurement unless its value or unit is not understood
+func (coin *Entity) addDimension(classification Entity, val, unit, defaultUnit string) {
+	number, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return
+	}
+
+	if unit == "" {
+		unit = defaultUnit
+	}
+
+	unitEntity, ok := units[unit]
+	if !ok {
+		return
+	}
+
+	coin.Dimension = append(coin.Dimension, &Entity{
+		Type:         "Dimension",
+		ClassifiedAs: []*Entity{ref(classification)},
+		Value:        &number,
+		Unit:         ref(unitEntity),
+	})
+}
+
+// production() describes where, when and by whose authority the coin was
+// struck, or nil if that is not known.  Rulers and issuers are people;
+// dynasties and states are groups.  They are named even without a URI.
+func production(typeDesc *simplenuds.TypeDesc) *Entity {
+	produced := &Entity{Type: "Production"}
+
+	if authority := typeDesc.Authority; authority != nil {
+		for _, persname := range authority.Persname {
+			if persname.Role == "authority" || persname.Role == "issuer" {
+				produced.CarriedOutBy = append(produced.CarriedOutBy, &Entity{ID: persname.Href, Type: "Person", Label: persname.Value})
+			}
+		}
+
+		for _, famname := range authority.Famname {
+			produced.CarriedOutBy = append(produced.CarriedOutBy, &Entity{ID: famname.Href, Type: "Group", Label: famname.Value})
+		}
+
+		for _, corpname := range authority.Corpname {
+			produced.CarriedOutBy = append(produced.CarriedOutBy, &Entity{ID: corpname.Href, Type: "Group", Label: corpname.Value})
+		}
+	}
+
+	if typeDesc.Geographic != nil {
+		for _, geogname := range typeDesc.Geographic.Geogname {
+			if geogname.Role == "mint" && geogname.Href != "" {
+				produced.TookPlaceAt = append(produced.TookPlaceAt, &Entity{ID: geogname.Href, Type: "Place", Label: geogname.Value})
+			}
+		}
+	}
+
+	produced.Timespan = timespan(typeDesc)
+
+	if len(produced.TookPlaceAt) == 0 && len(produced.CarriedOutBy) == 0 && produced.Timespan == nil {
+		return nil
+	}
+
+	return produced
+}
+
+func timespan(typeDesc *simplenuds.TypeDesc) *Entity {
+	var from, to simplenuds.Date
+
+	switch {
+	case typeDesc.Date != nil:
+		from, to = *typeDesc.Date, *typeDesc.Date
+	case typeDesc.DateRange != nil:
+		from, to = typeDesc.DateRange.FromDate, typeDesc.DateRange.ToDate
+	default:
+		return nil
+	}
+
+	if !gYearRE.MatchString(from.StandardDate) || !gYearRE.MatchString(to.StandardDate) {
+		return nil
+	}
+
+	label := from.Value
+	if to.Value != from.Value {
+		label += " - " + to.Value
+	}
+
+	return &Entity{
+		Type:            "TimeSpan",
+		IdentifiedBy:    []*Entity{{Type: "Name", Content: label}},
+		BeginOfTheBegin: from.StandardDate + "-01-01T00:00:00Z",
+		EndOfTheEnd:     to.StandardDate + "-12-31T23:59:59Z",
+	}
+}
+
+// representations() gives a VisualItem for each group of images, with
+// the side of the coin they show
+func representations(digRep *simplenuds.DigRep) []*Entity {
+	var visualItems []*Entity
+
+	for _, fileGrp := range digRep.FileSec.FileGrp {
+		visualItem := &Entity{Type: "VisualItem"}
+
+		switch strings.ToLower(fileGrp.USE) {
+		case "obverse":
+			visualItem.ClassifiedAs = []*Entity{ref(aatObverse)}
+		case "reverse":
+			visualItem.ClassifiedAs = []*Entity{ref(aatReverse)}
+		}
+
+		for _, file := range fileGrp.File {
+			// A IIIF service is not an image
+			if strings.EqualFold(file.USE, "iiif") {
+				continue
+			}
+
+			for _, flocat := range file.FLocat {
+				if flocat.Href == "" {
+					continue
+				}
+
+				digital := &Entity{
+					Type:        "DigitalObject",
+					AccessPoint: []*Entity{{ID: flocat.Href, Type: "DigitalObject"}},
+				}
+
+				visualItem.DigitallyShownBy = append(visualItem.DigitallyShownBy, digital)
+			}
+		}
+
+		if len(visualItem.DigitallyShownBy) > 0 {
+			visualItems = append(visualItems, visualItem)
+		}
+	}
+
+	return visualItems
+}
@@ -0,0 +1,142 @@
+package linkedart
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/esnible/csv-nuds/simplenuds"
+)
+
+var (
+	update = flag.Bool("update", false, "update the golden files of this test")
+)
+
+const base = "https://www.zeno.ru/id/"
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		name   string
+		nuds   string
+		golden string
+	}{
+		{
+			// The converter's output for the Zeno sample
+			name:   "zeno 264199",
+			nuds:   "../converter/testdata/nuds264199.xml.golden",
+			golden: "264199.json",
+		},
+		{
+			// Linked mint, date range and an obverse image
+			name:   "ANS 1922.999.73",
+			nuds:   "../simplenuds/testdata/1922.999.73.xml",
+			golden: "1922.999.73.json",
+		},
+	}
+
+	for _, tc := range tests {
+		f, err := os.Open(tc.nuds)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		nuds, err := simplenuds.Parse(f)
+		f.Close()
+
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+
+		data, err := json.MarshalIndent(Describe(nuds, base), "", "  ")
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+
+		got := string(data) + "\n"
+
+		want := goldenValue(t, tc.golden, got, *update)
+		if got != want {
+			t.Errorf("%s: Want:\n%s\nGot:\n%s", tc.name, want, got)
+		}
+	}
+}
+
+func TestProductionAuthority(t *testing.T) {
+	var nuds simplenuds.NUDS
+
+	nuds.DescMeta.TypeDesc.Authority = &simplenuds.Authority{
+		Persname: []simplenuds.Persname{
+			{Role: "authority", Href: "http://nomisma.org/id/augustus", Value: "Augustus"},
+			{Role: "issuer", Value: "C. Antistius Vetus"},
+		},
+		Famname: []simplenuds.Famname{{Role: "dynasty", Value: "Julio-Claudian"}},
+	}
+
+	produced := Describe(&nuds, base).ProducedBy
+	if produced == nil {
+		t.Fatal("no production")
+	}
+
+	data, err := json.Marshal(produced.CarriedOutBy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `[{"id":"http://nomisma.org/id/augustus","type":"Person","_label":"Augustus"},` +
+		`{"type":"Person","_label":"C. Antistius Vetus"},` +
+		`{"type":"Group","_label":"Julio-Claudian"}]`
+	if string(data) != want {
+		t.Errorf("got carried_out_by %s\nwant %s", data, want)
+	}
+}
+
+func TestAccessionNumber(t *testing.T) {
+	var nuds simplenuds.NUDS
+
+	nuds.Control.RecordID = "58627"
+	nuds.DescMeta.AdminDesc = &simplenuds.AdminDesc{Identifier: "1922.999.73"}
+
+	data, err := json.Marshal(Describe(&nuds, base).IdentifiedBy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `[{"type":"Identifier","content":"58627"},` +
+		`{"type":"Identifier","classified_as":[{"id":"http://vocab.getty.edu/aat/300312355","type":"Type","_label":"accession numbers"}],"content":"1922.999.73"}]`
+	if string(data) != want {
+		t.Errorf("got identified_by %s\nwant %s", data, want)
+	}
+}
+
+func TestSharedEntities(t *testing.T) {
+	var nuds simplenuds.NUDS
+
+	// Changing one record's classification must not change the next
+	Describe(&nuds, base).ClassifiedAs[0].Label = "changed"
+
+	if label := Describe(&nuds, base).ClassifiedAs[0].Label; label != "coins (money)" {
+		t.Errorf("got label %q", label)
+	}
+}
+
+func goldenValue(t *testing.T, goldenFile string, actual string, update bool) string {
+	t.Helper()
+	goldenPath := "testdata/" + goldenFile + ".golden"
+
+	if update {
+		err := os.WriteFile(goldenPath, []byte(actual), 0644)
+		if err != nil {
+			t.Fatalf("Error writing to file %s: %s", goldenPath, err)
+		}
+
+		return actual
+	}
+
+	content, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("Error reading file %s: %s", goldenPath, err)
+	}
+
+	return string(content)
+}
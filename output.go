@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+
+	"github.com/esnible/csv-nuds/linkedart"
+	"github.com/esnible/csv-nuds/rdf"
+	"github.com/esnible/csv-nuds/simplenuds"
+)
+
+// An outputFormat turns a record into the contents of a file
+type outputFormat struct {
+	ext    string
+	encode func(nuds *simplenuds.NUDS) ([]byte, error)
+}
+
+// newOutputFormat() looks up a -format.  RDF and Linked Art name each
+// coin by appending its record ID to base, which must be an absolute URI.
+func newOutputFormat(name, base string) (outputFormat, error) {
+	switch name {
+	case "turtle", "rdfxml", "linkedart":
+		if u, err := url.Parse(base); err != nil || !u.IsAbs() {
+			return outputFormat{}, fmt.Errorf("-format %s needs an absolute -base URI such as https://www.zeno.ru/id/", name)
+		}
+	}
+
+	switch name {
+	case "nuds":
+		return outputFormat{ext: ".xml", encode: encodeNUDS}, nil
+	case "turtle":
+		return outputFormat{ext: ".ttl", encode: func(nuds *simplenuds.NUDS) ([]byte, error) {
+			var buf bytes.Buffer
+			err := rdf.WriteTurtle(&buf, rdf.Describe(nuds, base))
+
+			return buf.Bytes(), err
+		}}, nil
+	case "rdfxml":
+		return outputFormat{ext: ".rdf", encode: func(nuds *simplenuds.NUDS) ([]byte, error) {
+			var buf bytes.Buffer
+			err := rdf.WriteRDFXML(&buf, rdf.Describe(nuds, base))
+
+			return buf.Bytes(), err
+		}}, nil
+	case "linkedart":
+		return outputFormat{ext: ".json", encode: func(nuds *simplenuds.NUDS) ([]byte, error) {
+			data, err := json.MarshalIndent(linkedart.Describe(nuds, base), "", "  ")
+
+			return append(data, '\n'), err
+		}}, nil
+	}
+
+	return outputFormat{}, fmt.Errorf("unknown output format %q; use nuds, turtle, rdfxml or linkedart", name)
+}
+
+func encodeNUDS(nuds *simplenuds.NUDS) ([]byte, error) {
+	return xml.MarshalIndent(nuds, " ", "  ")
+}
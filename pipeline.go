@@ -0,0 +1,239 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/esnible/csv-nuds/converter"
+	"github.com/esnible/csv-nuds/simplenuds"
+)
+
+// A row of the CSV, numbered in the order it was read
+type row struct {
+	seq int
+
+	// Line in the CSV file where the row starts
+	line int
+
+	coin map[string]string
+
+	// A problem reading this row
+	err error
+}
+
+// The outcome of converting a row
+type result struct {
+	row
+
+	recordID    string
+	diagnostics converter.Diagnostics
+
+	// The encoded record, or nil if the record could not be converted or is invalid
+	data []byte
+
+	// Compared with the previous run, if there is one
+	status recordStatus
+}
+
+// pipeline converts rows with a pool of workers
+type pipeline struct {
+	conv   *converter.Converter
+	encode func(*simplenuds.NUDS) ([]byte, error)
+
+	// If set, each record is compared with the file from the previous run
+	previous *outputDir
+
+	workers int
+}
+
+// records are rows of strings from a CSV file or a spreadsheet
+type records interface {
+	Read() ([]string, error)
+
+	// FieldPos() gives the line of a field of the last row read
+	FieldPos(field int) (line, column int)
+}
+
+// rowReader returns the rows of a CSV file or spreadsheet as coins
+type rowReader struct {
+	reader records
+	cols   map[int]string
+
+	// Values for every coin
+	everyCols map[int]string
+	every     []string
+}
+
+// next() returns the next row, or io.EOF
+func (rr *rowReader) next() (row, error) {
+	rec, err := rr.reader.Read()
+	if err == io.EOF {
+		return row{}, err
+	}
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		// Report this row and carry on with the next
+		return row{line: parseErr.StartLine, err: err}, nil
+	}
+
+	if err != nil {
+		return row{}, err
+	}
+
+	line, _ := rr.reader.FieldPos(0)
+
+	return row{
+		line: line,
+		coin: generateMap(rr.cols, rec, rr.everyCols, rr.every),
+	}, nil
+}
+
+// run() converts and encodes rows, calling emit() for each result in the
+// order the rows were read, and returns a report of the run.  It stops at
+// the first error from emit() or from reading.
+func (p *pipeline) run(next func() (row, error), emit func(result) error) (*converter.Report, error) {
+	workers := p.workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	rows := make(chan row, 2*workers)
+	results := make(chan result, 2*workers)
+	done := make(chan struct{})
+
+	// One slot for each row read but not yet emitted.  A slow row holds
+	// back emitting the rows after it, so this stops the reader getting
+	// far ahead and the results waiting for it piling up.
+	slots := make(chan struct{}, 4*workers)
+
+	var readErr error
+
+	// Reader
+	go func() {
+		defer close(rows)
+
+		for seq := 0; ; seq++ {
+			select {
+			case slots <- struct{}{}:
+			case <-done:
+				return
+			}
+
+			r, err := next()
+			if err == io.EOF {
+				return
+			}
+
+			if err != nil {
+				readErr = err
+				return
+			}
+
+			r.seq = seq
+
+			select {
+			case rows <- r:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	// Converters
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for r := range rows {
+				results <- p.convertRow(r)
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Writer, restoring the order of the rows
+	report := converter.NewReport()
+
+	var emitErr error
+
+	pending := map[int]result{}
+	nextSeq := 0
+
+	for res := range results {
+		if emitErr != nil {
+			// Drain so the workers can finish
+			continue
+		}
+
+		pending[res.seq] = res
+
+		for {
+			res, ok := pending[nextSeq]
+			if !ok {
+				break
+			}
+
+			delete(pending, nextSeq)
+			nextSeq++
+			<-slots
+
+			report.Add(res.diagnostics)
+
+			if emitErr = emit(res); emitErr != nil {
+				close(done)
+				break
+			}
+		}
+	}
+
+	if emitErr != nil {
+		return report, emitErr
+	}
+
+	return report, readErr
+}
+
+func (p *pipeline) convertRow(r row) result {
+	res := result{row: r}
+	if r.err != nil {
+		return res
+	}
+
+	nuds, diagnostics, err := p.conv.GenerateNUDS(r.coin)
+	if err != nil {
+		res.err = err
+		return res
+	}
+
+	for i := range diagnostics {
+		diagnostics[i].Line = r.line
+	}
+
+	res.recordID = nuds.Control.RecordID
+	res.diagnostics = diagnostics
+
+	// Don't write records that failed the schema check
+	if diagnostics.HasErrors() {
+		return res
+	}
+
+	if p.previous != nil {
+		res.data, res.status, res.err = p.previous.encode(nuds, p.encode)
+		return res
+	}
+
+	res.data, res.err = p.encode(nuds)
+
+	return res
+}
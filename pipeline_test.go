@@ -0,0 +1,275 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/esnible/csv-nuds/converter"
+	"github.com/esnible/csv-nuds/simplenuds"
+)
+
+// zenoRows() reads the example CSV into memory
+func zenoRows(t testing.TB) []row {
+	t.Helper()
+
+	reader, cols, err := csvReader("data/zeno.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := &rowReader{reader: reader, cols: cols}
+
+	var rows []row
+
+	for {
+		r, err := rr.next()
+		if err == io.EOF {
+			return rows
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rows = append(rows, r)
+	}
+}
+
+// sliceReader() returns rows from memory, repeated n times
+func sliceReader(rows []row, n int) func() (row, error) {
+	i := 0
+
+	return func() (row, error) {
+		if i >= len(rows)*n {
+			return row{}, io.EOF
+		}
+
+		r := rows[i%len(rows)]
+		i++
+
+		return r, nil
+	}
+}
+
+func newTestConverter(t testing.TB) *converter.Converter {
+	t.Helper()
+
+	conv := converter.NewConverter(time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC))
+	conv.CheckSchema = true
+
+	return &conv
+}
+
+func TestPipelineOrder(t *testing.T) {
+	rows := zenoRows(t)
+
+	collect := func(workers int) []result {
+		var results []result
+
+		p := &pipeline{conv: newTestConverter(t), encode: encodeNUDS, workers: workers}
+
+		_, err := p.run(sliceReader(rows, 5), func(res result) error {
+			results = append(results, res)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return results
+	}
+
+	serial := collect(1)
+	parallel := collect(8)
+
+	if len(serial) != 5*len(rows) || len(parallel) != len(serial) {
+		t.Fatalf("got %d and %d results, expected %d", len(serial), len(parallel), 5*len(rows))
+	}
+
+	for i := range serial {
+		if parallel[i].seq != i || parallel[i].line != serial[i].line {
+			t.Fatalf("result %d is row %d line %d, expected line %d", i, parallel[i].seq, parallel[i].line, serial[i].line)
+		}
+
+		if string(parallel[i].data) != string(serial[i].data) {
+			t.Errorf("result %d (record %s) differs between 1 and 8 workers", i, serial[i].recordID)
+		}
+	}
+}
+
+// Each run has its own report, even with a shared Converter
+func TestPipelineReport(t *testing.T) {
+	rows := []row{
+		{line: 2, coin: map[string]string{"id": "1", "grade": "VF"}},
+		{line: 3, coin: map[string]string{"id": "2", "grade": "F", "keywords": "Sasanian"}},
+	}
+
+	p := &pipeline{conv: newTestConverter(t), encode: encodeNUDS, workers: 4}
+
+	for run := 0; run < 2; run++ {
+		report, err := p.run(sliceReader(rows, 3), func(result) error { return nil })
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := map[string]int{"grade": 6, "keywords": 3}
+		if got := report.UnknownColumns(); !reflect.DeepEqual(got, want) {
+			t.Errorf("run %d: unknown columns %v, want %v", run, got, want)
+		}
+	}
+}
+
+func TestPipelineErrors(t *testing.T) {
+	input := "id,weight\n" +
+		"1,3.5\n" +
+		"2,\"bad\"quote\n" +
+		"3,heavy\n" +
+		"4,4.1\n"
+
+	reader := csv.NewReader(strings.NewReader(input))
+
+	header, err := reader.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := &rowReader{reader: reader, cols: generateColumnLookup(header)}
+
+	// These rows are too sparse to be valid NUDS
+	conv := newTestConverter(t)
+	conv.CheckSchema = false
+
+	var problems []string
+
+	p := &pipeline{conv: conv, encode: encodeNUDS, workers: 4}
+
+	_, err = p.run(rr.next, func(res result) error {
+		if res.err != nil {
+			problems = append(problems, fmt.Sprintf("%d: error", res.line))
+		}
+
+		for _, diagnostic := range res.diagnostics {
+			problems = append(problems, fmt.Sprintf("%d: %s", diagnostic.Line, diagnostic.Code))
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"3: error", "4: " + converter.CodeInvalidWeight}
+	if strings.Join(problems, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("got problems %q, expected %q", problems, expected)
+	}
+}
+
+func TestPipelineStops(t *testing.T) {
+	rows := zenoRows(t)
+	stop := errors.New("disk full")
+
+	count := 0
+
+	p := &pipeline{conv: newTestConverter(t), encode: encodeNUDS, workers: 4}
+
+	_, err := p.run(sliceReader(rows, 100), func(res result) error {
+		count++
+		if count == 3 {
+			return stop
+		}
+
+		return nil
+	})
+
+	if err != stop {
+		t.Errorf("got error %v, expected %v", err, stop)
+	}
+
+	if count != 3 {
+		t.Errorf("emitted %d results after the error", count-3)
+	}
+}
+
+// A slow record must not let the reader run ahead without limit
+func TestPipelineBounded(t *testing.T) {
+	rows := zenoRows(t)
+	first := rows[0].coin["id"]
+
+	const workers, repeats = 2, 50
+
+	release := make(chan struct{})
+
+	var read int32
+
+	next := sliceReader(rows, repeats)
+
+	p := &pipeline{
+		conv: newTestConverter(t),
+		encode: func(nuds *simplenuds.NUDS) ([]byte, error) {
+			if nuds.Control.RecordID == first {
+				<-release
+			}
+
+			return encodeNUDS(nuds)
+		},
+		workers: workers,
+	}
+
+	go func() {
+		// Wait for the reader to stop, then let the first record finish
+		for last := int32(-1); last != atomic.LoadInt32(&read); {
+			last = atomic.LoadInt32(&read)
+			time.Sleep(20 * time.Millisecond)
+		}
+
+		close(release)
+	}()
+
+	count := 0
+
+	_, err := p.run(func() (row, error) {
+		atomic.AddInt32(&read, 1)
+		return next()
+	}, func(res result) error {
+		if count == 0 {
+			if n := atomic.LoadInt32(&read); n > 4*workers+1 {
+				t.Errorf("read %d rows while the first was converting", n)
+			}
+		}
+
+		count++
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if count != repeats*len(rows) {
+		t.Errorf("emitted %d results, want %d", count, repeats*len(rows))
+	}
+}
+
+func BenchmarkPipeline(b *testing.B) {
+	rows := zenoRows(b)
+
+	for _, workers := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("j=%d", workers), func(b *testing.B) {
+			p := &pipeline{conv: newTestConverter(b), encode: encodeNUDS, workers: workers}
+
+			for i := 0; i < b.N; i++ {
+				_, err := p.run(sliceReader(rows, 10), func(result) error { return nil })
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/esnible/csv-nuds/exist"
+)
+
+// newPublisher() uploads to the eXist-db at baseURL, reading the password
+// from $EXIST_PASSWORD so it does not appear in the process list
+func newPublisher(baseURL, collection, user string, connections, retries int) *exist.Publisher {
+	client := &exist.Client{
+		BaseURL:    baseURL,
+		Collection: collection,
+		User:       user,
+		Password:   os.Getenv("EXIST_PASSWORD"),
+		Retries:    retries,
+		Backoff:    time.Second,
+	}
+
+	return exist.NewPublisher(context.Background(), client, connections)
+}
+
+// printSummary() reports the records that could not be published.  It
+// returns false if there were any.
+func printSummary(w io.Writer, summary exist.Summary) bool {
+	fmt.Fprintf(w, "published %d records", summary.Published)
+
+	if len(summary.Failures) == 0 {
+		fmt.Fprintln(w)
+		return true
+	}
+
+	fmt.Fprintf(w, "; %d failed:\n", len(summary.Failures))
+
+	for _, failure := range summary.Failures {
+		fmt.Fprintf(w, "  record %s: %s\n", failure.RecordID, failure.Err)
+	}
+
+	return false
+}
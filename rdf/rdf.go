@@ -0,0 +1,306 @@
+// Package rdf describes NUDS records with the Nomisma ontology, so they
+// can be loaded into Nomisma's aggregation and SPARQL endpoints.
+// See http://nomisma.org/ontology and http://nomisma.org/documentation/contribute
+package rdf
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/esnible/csv-nuds/simplenuds"
+)
+
+// A Prefix abbreviates a namespace, e.g. nmo: for http://nomisma.org/ontology#
+type Prefix struct {
+	Name      string
+	Namespace string
+}
+
+// The prefixes of the predicates, types and datatypes we write.  Names
+// in Resources are always written with one of these.
+var Prefixes = []Prefix{
+	{"dcterms", "http://purl.org/dc/terms/"},
+	{"foaf", "http://xmlns.com/foaf/0.1/"},
+	{"nmo", "http://nomisma.org/ontology#"},
+	{"rdf", "http://www.w3.org/1999/02/22-rdf-syntax-ns#"},
+	{"xsd", "http://www.w3.org/2001/XMLSchema#"},
+}
+
+// A Resource is a subject and what is said about it.  A Resource without
+// an IRI is a blank node, written where it is used.
+type Resource struct {
+	IRI string
+
+	// Classes, as prefixed names such as "nmo:NumismaticObject"
+	Types []string
+
+	Properties []Property
+}
+
+// A Property is a predicate, as a prefixed name, and its object
+type Property struct {
+	Predicate string
+	Object    Term
+}
+
+// A Term is the object of a Property: an IRI, a literal or a blank node
+type Term struct {
+	IRI string
+
+	Literal string
+	// Language of a plain literal
+	Lang string
+	// Datatype of a typed literal, as a prefixed name such as "xsd:decimal"
+	Datatype string
+
+	Resource *Resource
+}
+
+func (resource *Resource) add(predicate string, object Term) {
+	resource.Properties = append(resource.Properties, Property{Predicate: predicate, Object: object})
+}
+
+func iri(val string) Term {
+	return Term{IRI: val}
+}
+
+func literal(val, lang string) Term {
+	return Term{Literal: val, Lang: lang}
+}
+
+func typed(val, datatype string) Term {
+	return Term{Literal: val, Datatype: datatype}
+}
+
+var decimalRE = regexp.MustCompile(`^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)$`)
+
+// gYear as written by simplenuds, e.g. "0591" or "-0100"
+var gYearRE = regexp.MustCompile(`^-?[0-9]{4,}$`)
+
+// Describe() gives a nmo:NumismaticObject for a physical NUDS record.
+// The object's IRI is the record ID appended to base, e.g.
+// "http://example.org/id/" gives http://example.org/id/264199.  Names
+// without Nomisma (or other) URIs, such as an uncertain mint, are left out.
+func Describe(nuds *simplenuds.NUDS, base string) *Resource {
+	coin := &Resource{
+		IRI:   base + url.PathEscape(nuds.Control.RecordID),
+		Types: []string{"nmo:NumismaticObject"},
+	}
+
+	for _, title := range nuds.DescMeta.Title {
+		if title.Value != "" {
+			coin.add("dcterms:title", literal(title.Value, title.Lang))
+		}
+	}
+
+	coin.add("dcterms:identifier", literal(nuds.Control.RecordID, ""))
+
+	typeDesc := &nuds.DescMeta.TypeDesc
+
+	if typeDesc.HRef != "" {
+		coin.add("nmo:hasTypeSeriesItem", iri(typeDesc.HRef))
+	}
+
+	if item := typeDesc.TypeSeriesItem; item != nil && item.Href != "" && item.Href != typeDesc.HRef {
+		coin.add("nmo:hasTypeSeriesItem", iri(item.Href))
+	}
+
+	describeDates(coin, typeDesc)
+
+	for _, denomination := range typeDesc.Denomination {
+		if denomination.HRef != "" {
+			coin.add("nmo:hasDenomination", iri(denomination.HRef))
+		}
+	}
+
+	for _, material := range typeDesc.Material {
+		if material.HRef != "" {
+			coin.add("nmo:hasMaterial", iri(material.HRef))
+		}
+	}
+
+	if typeDesc.Authority != nil {
+		describeAuthority(coin, typeDesc.Authority)
+	}
+
+	if typeDesc.Geographic != nil {
+		for _, geogname := range typeDesc.Geographic.Geogname {
+			if geogname.Href == "" {
+				continue
+			}
+
+			switch geogname.Role {
+			case "mint":
+				coin.add("nmo:hasMint", iri(geogname.Href))
+			case "region":
+				coin.add("nmo:hasRegion", iri(geogname.Href))
+			}
+		}
+	}
+
+	if physDesc := nuds.DescMeta.PhysDesc; physDesc != nil && physDesc.MeasurementsSet != nil {
+		measurements := physDesc.MeasurementsSet
+
+		if measurements.Weight != nil && decimalRE.MatchString(measurements.Weight.Value) {
+			coin.add("nmo:hasWeight", typed(measurements.Weight.Value, "xsd:decimal"))
+		}
+
+		if measurements.Diameter != nil && decimalRE.MatchString(measurements.Diameter.Value) {
+			coin.add("nmo:hasDiameter", typed(measurements.Diameter.Value, "xsd:decimal"))
+		}
+	}
+
+	sides := map[string]*Resource{
+		"obverse": describeSide(typeDesc.Obverse),
+		"reverse": describeSide(typeDesc.Reverse),
+	}
+
+	if nuds.DigRep != nil {
+		describeImages(coin, nuds.DigRep, sides)
+	}
+
+	for _, side := range []struct{ use, predicate string }{
+		{"obverse", "nmo:hasObverse"},
+		{"reverse", "nmo:hasReverse"},
+	} {
+		if resource := sides[side.use]; len(resource.Properties) > 0 {
+			coin.add(side.predicate, Term{Resource: resource})
+		}
+	}
+
+	return coin
+}
+
+// describeDates() gives the earliest and latest year of production
+func describeDates(coin *Resource, typeDesc *simplenuds.TypeDesc) {
+	var from, to string
+
+	switch {
+	case typeDesc.Date != nil:
+		from, to = typeDesc.Date.StandardDate, typeDesc.Date.StandardDate
+	case typeDesc.DateRange != nil:
+		from, to = typeDesc.DateRange.FromDate.StandardDate, typeDesc.DateRange.ToDate.StandardDate
+	}
+
+	if gYearRE.MatchString(from) {
+		coin.add("nmo:hasStartDate", typed(from, "xsd:gYear"))
+	}
+
+	if gYearRE.MatchString(to) {
+		coin.add("nmo:hasEndDate", typed(to, "xsd:gYear"))
+	}
+}
+
+// describeAuthority() links the rulers, dynasties and states that issued
+// the coin with nmo:hasAuthority and other officials with nmo:hasIssuer
+func describeAuthority(coin *Resource, authority *simplenuds.Authority) {
+	for _, persname := range authority.Persname {
+		if persname.Href == "" {
+			continue
+		}
+
+		switch persname.Role {
+		case "authority":
+			coin.add("nmo:hasAuthority", iri(persname.Href))
+		case "issuer":
+			coin.add("nmo:hasIssuer", iri(persname.Href))
+		}
+	}
+
+	for _, corpname := range authority.Corpname {
+		if corpname.Href != "" {
+			coin.add("nmo:hasAuthority", iri(corpname.Href))
+		}
+	}
+
+	for _, famname := range authority.Famname {
+		if famname.Href != "" {
+			coin.add("nmo:hasAuthority", iri(famname.Href))
+		}
+	}
+}
+
+// describeSide() gives the blank node of an <obverse> or <reverse>, with
+// what is depicted, its legend and the person portrayed
+func describeSide(side *simplenuds.Side) *Resource {
+	resource := &Resource{}
+	if side == nil {
+		return resource
+	}
+
+	if side.Type != nil {
+		for _, description := range side.Type.Description {
+			if description.Value != "" {
+				resource.add("dcterms:description", Term{Literal: description.Value, Lang: description.Lang})
+			}
+		}
+	}
+
+	for _, legend := range side.Legend {
+		text := strings.TrimSpace(legend.Value)
+
+		if legend.TEI != nil {
+			var lines []string
+			for _, ab := range legend.TEI.Ab {
+				lines = append(lines, ab.Value)
+			}
+
+			text = strings.Join(lines, " ")
+		}
+
+		if text != "" {
+			resource.add("nmo:hasLegend", Term{Literal: text})
+		}
+	}
+
+	for _, persname := range side.Persname {
+		if persname.Role == "portrait" && persname.Href != "" {
+			resource.add("nmo:hasPortrait", iri(persname.Href))
+		}
+	}
+
+	return resource
+}
+
+// describeImages() adds the images of each side to its blank node in
+// sides, and images of the whole coin to the coin itself
+func describeImages(coin *Resource, digRep *simplenuds.DigRep, sides map[string]*Resource) {
+	for _, fileGrp := range digRep.FileSec.FileGrp {
+		subject := coin
+
+		use := strings.ToLower(fileGrp.USE)
+		if use == "obverse" || use == "reverse" {
+			subject = sides[use]
+		}
+
+		for _, file := range fileGrp.File {
+			// A IIIF service is not an image
+			if strings.EqualFold(file.USE, "iiif") {
+				continue
+			}
+
+			predicate := "foaf:depiction"
+			if strings.EqualFold(file.USE, "thumbnail") {
+				predicate = "foaf:thumbnail"
+			}
+
+			for _, flocat := range file.FLocat {
+				if flocat.Href != "" {
+					subject.add(predicate, iri(flocat.Href))
+				}
+			}
+		}
+	}
+}
+
+// expand() turns a prefixed name into an IRI
+func expand(name string) string {
+	for _, prefix := range Prefixes {
+		if strings.HasPrefix(name, prefix.Name+":") {
+			return prefix.Namespace + name[len(prefix.Name)+1:]
+		}
+	}
+
+	return name
+}
@@ -0,0 +1,221 @@
+package rdf
+
+import (
+	"bytes"
+	"encoding/xml"
+	"flag"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/esnible/csv-nuds/simplenuds"
+)
+
+var (
+	update = flag.Bool("update", false, "update the golden files of this test")
+)
+
+const base = "https://www.zeno.ru/id/"
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name   string
+		nuds   string
+		golden string
+	}{
+		{
+			// The converter's output for the Zeno sample
+			name:   "zeno 264199",
+			nuds:   "../converter/testdata/nuds264199.xml.golden",
+			golden: "264199",
+		},
+		{
+			// Linked mint, date range and an obverse image
+			name:   "ANS 1922.999.73",
+			nuds:   "../simplenuds/testdata/1922.999.73.xml",
+			golden: "1922.999.73",
+		},
+	}
+
+	for _, tc := range tests {
+		nuds := readNUDS(t, tc.nuds)
+		coin := Describe(nuds, base)
+
+		for _, format := range []struct {
+			ext   string
+			write func(io.Writer, ...*Resource) error
+		}{
+			{"ttl", WriteTurtle},
+			{"rdf", WriteRDFXML},
+		} {
+			var buf bytes.Buffer
+			if err := format.write(&buf, coin); err != nil {
+				t.Fatalf("%s: %v", tc.name, err)
+			}
+
+			want := goldenValue(t, tc.golden+"."+format.ext, buf.String(), *update)
+			if buf.String() != want {
+				t.Errorf("%s: Want:\n%s\nGot:\n%s", tc.name, want, buf.String())
+			}
+
+			if format.ext == "rdf" {
+				checkWellFormed(t, tc.name, buf.Bytes())
+			}
+		}
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	nuds := simplenuds.NewNUDS("physical", time.Time{})
+	nuds.Control.RecordID = "A 12"
+	nuds.DescMeta.TypeDesc = simplenuds.TypeDesc{
+		Type:     "simple",
+		HRef:     "http://numismatics.org/ocre/id/ric.1(2).aug.1a",
+		Date:     &simplenuds.Date{StandardDate: "-0027", Value: "27 BC"},
+		Material: []simplenuds.Material{{Text: "Gilt"}},
+		TypeSeriesItem: &simplenuds.TypeSeriesItem{
+			Href: "http://numismatics.org/ocre/id/ric.1(2).aug.1b", Value: "RIC I (second edition) Augustus 1B",
+		},
+		Authority: &simplenuds.Authority{
+			Persname: []simplenuds.Persname{
+				{Role: "authority", Href: "http://nomisma.org/id/augustus", Value: "Augustus"},
+				{Role: "issuer", Value: "C. Antistius Vetus"},
+			},
+		},
+		Obverse: &simplenuds.Side{
+			Legend: []simplenuds.Legend{{TEI: &simplenuds.TEIDiv{
+				Type: "edition",
+				Ab:   []simplenuds.TEIAb{{Value: "CAESAR"}, {Value: "AVGVSTVS"}},
+			}}},
+			Type: &simplenuds.SideType{Description: []simplenuds.Description{{Lang: "en", Value: "Head of Augustus right"}}},
+			Persname: []simplenuds.Persname{
+				{Role: "portrait", Href: "http://nomisma.org/id/augustus", Value: "Augustus"},
+				{Role: "portrait", Value: "Livia"},
+			},
+		},
+	}
+	nuds.DescMeta.PhysDesc = &simplenuds.PhysDesc{
+		MeasurementsSet: &simplenuds.MeasurementsSet{
+			Weight: &simplenuds.Weight{Units: "g", Value: "3,7"},
+		},
+	}
+
+	coin := Describe(&nuds, base)
+
+	if coin.IRI != base+"A%2012" {
+		t.Errorf("got IRI %s", coin.IRI)
+	}
+
+	var got []string
+	for _, property := range coin.Properties {
+		got = append(got, property.Predicate+" "+property.Object.IRI+property.Object.Literal)
+	}
+
+	// The material and issuer without a URI and the unparseable weight are left out
+	expected := []string{
+		"dcterms:identifier A 12",
+		"nmo:hasTypeSeriesItem http://numismatics.org/ocre/id/ric.1(2).aug.1a",
+		"nmo:hasTypeSeriesItem http://numismatics.org/ocre/id/ric.1(2).aug.1b",
+		"nmo:hasStartDate -0027",
+		"nmo:hasEndDate -0027",
+		"nmo:hasAuthority http://nomisma.org/id/augustus",
+		"nmo:hasObverse ",
+	}
+
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("got properties\n%s\nexpected\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+
+	obverse := coin.Properties[len(coin.Properties)-1].Object.Resource
+	if obverse == nil {
+		t.Fatal("no obverse")
+	}
+
+	got = nil
+	for _, property := range obverse.Properties {
+		got = append(got, property.Predicate+" "+property.Object.IRI+property.Object.Literal)
+	}
+
+	// The portrait without a URI is left out
+	expected = []string{
+		"dcterms:description Head of Augustus right",
+		"nmo:hasLegend CAESAR AVGVSTVS",
+		"nmo:hasPortrait http://nomisma.org/id/augustus",
+	}
+
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("got obverse properties\n%s\nexpected\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestTurtleIRI(t *testing.T) {
+	for val, expected := range map[string]string{
+		base + "58627":                    "<https://www.zeno.ru/id/58627>",
+		"http://example.org/a b|c":        "<http://example.org/a%20b%7Cc>",
+		"http://example.org/{x}^`\\\"<y>": "<http://example.org/%7Bx%7D%5E%60%5C%22%3Cy%3E>",
+		"http://example.org/Драхма":       "<http://example.org/Драхма>",
+	} {
+		if got := turtleIRI(val); got != expected {
+			t.Errorf("turtleIRI(%q) = %s, expected %s", val, got, expected)
+		}
+	}
+}
+
+func readNUDS(t *testing.T, name string) *simplenuds.NUDS {
+	t.Helper()
+
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	nuds, err := simplenuds.Parse(f)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+
+	return nuds
+}
+
+// checkWellFormed() reads every token of an XML document
+func checkWellFormed(t *testing.T, name string, data []byte) {
+	t.Helper()
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			return
+		}
+
+		if err != nil {
+			t.Errorf("%s: RDF/XML is not well-formed: %v", name, err)
+			return
+		}
+	}
+}
+
+func goldenValue(t *testing.T, goldenFile string, actual string, update bool) string {
+	t.Helper()
+	goldenPath := "testdata/" + goldenFile + ".golden"
+
+	if update {
+		err := os.WriteFile(goldenPath, []byte(actual), 0644)
+		if err != nil {
+			t.Fatalf("Error writing to file %s: %s", goldenPath, err)
+		}
+
+		return actual
+	}
+
+	content, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("Error reading file %s: %s", goldenPath, err)
+	}
+
+	return string(content)
+}
@@ -0,0 +1,88 @@
+package rdf
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// WriteRDFXML() writes resources as RDF/XML, https://www.w3.org/TR/rdf-syntax-grammar/
+func WriteRDFXML(w io.Writer, resources ...*Resource) error {
+	var sb strings.Builder
+
+	sb.WriteString(xml.Header)
+	sb.WriteString("<rdf:RDF")
+
+	for _, prefix := range Prefixes {
+		sb.WriteString(" xmlns:" + prefix.Name + "=\"" + xmlEscape(prefix.Namespace) + "\"")
+	}
+
+	sb.WriteString(">\n")
+
+	for _, resource := range resources {
+		writeRDFXMLNode(&sb, resource, "  ")
+	}
+
+	sb.WriteString("</rdf:RDF>\n")
+
+	_, err := io.WriteString(w, sb.String())
+
+	return err
+}
+
+// writeRDFXMLNode() writes a node element, named by the first type if any
+func writeRDFXMLNode(sb *strings.Builder, resource *Resource, indent string) {
+	element := "rdf:Description"
+	types := resource.Types
+
+	if len(types) > 0 {
+		element, types = types[0], types[1:]
+	}
+
+	sb.WriteString(indent + "<" + element)
+
+	if resource.IRI != "" {
+		sb.WriteString(` rdf:about="` + xmlEscape(resource.IRI) + `"`)
+	}
+
+	sb.WriteString(">\n")
+
+	for _, typ := range types {
+		sb.WriteString(indent + `  <rdf:type rdf:resource="` + xmlEscape(expand(typ)) + "\"/>\n")
+	}
+
+	for _, property := range resource.Properties {
+		object := property.Object
+
+		sb.WriteString(indent + "  <" + property.Predicate)
+
+		switch {
+		case object.Resource != nil:
+			sb.WriteString(">\n")
+			writeRDFXMLNode(sb, object.Resource, indent+"    ")
+			sb.WriteString(indent + "  </" + property.Predicate + ">\n")
+
+			continue
+		case object.IRI != "":
+			sb.WriteString(` rdf:resource="` + xmlEscape(object.IRI) + "\"/>\n")
+
+			continue
+		case object.Lang != "":
+			sb.WriteString(` xml:lang="` + xmlEscape(object.Lang) + `"`)
+		case object.Datatype != "":
+			sb.WriteString(` rdf:datatype="` + xmlEscape(expand(object.Datatype)) + `"`)
+		}
+
+		sb.WriteString(">" + xmlEscape(object.Literal) + "</" + property.Predicate + ">\n")
+	}
+
+	sb.WriteString(indent + "</" + element + ">\n")
+}
+
+func xmlEscape(val string) string {
+	var sb strings.Builder
+
+	_ = xml.EscapeText(&sb, []byte(val))
+
+	return sb.String()
+}
@@ -0,0 +1,102 @@
+package rdf
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteTurtle() writes resources as Turtle, https://www.w3.org/TR/turtle/
+func WriteTurtle(w io.Writer, resources ...*Resource) error {
+	var sb strings.Builder
+
+	for _, prefix := range Prefixes {
+		fmt.Fprintf(&sb, "@prefix %s: <%s> .\n", prefix.Name, prefix.Namespace)
+	}
+
+	for _, resource := range resources {
+		sb.WriteString("\n")
+
+		if resource.IRI == "" {
+			sb.WriteString("[]")
+		} else {
+			sb.WriteString(turtleIRI(resource.IRI))
+		}
+
+		writeTurtleProperties(&sb, resource, " ", "    ")
+		sb.WriteString(" .\n")
+	}
+
+	_, err := io.WriteString(w, sb.String())
+
+	return err
+}
+
+// writeTurtleProperties() writes the predicate-object list of a subject,
+// starting with lead
+func writeTurtleProperties(sb *strings.Builder, resource *Resource, lead, indent string) {
+	separator := lead
+
+	if len(resource.Types) > 0 {
+		sb.WriteString(separator + "a " + strings.Join(resource.Types, ", "))
+		separator = " ;\n" + indent
+	}
+
+	for _, property := range resource.Properties {
+		sb.WriteString(separator + property.Predicate + " ")
+		separator = " ;\n" + indent
+
+		object := property.Object
+
+		switch {
+		case object.Resource != nil:
+			sb.WriteString("[")
+			writeTurtleProperties(sb, object.Resource, "\n"+indent+"    ", indent+"    ")
+			sb.WriteString("\n" + indent + "]")
+		case object.IRI != "":
+			sb.WriteString(turtleIRI(object.IRI))
+		default:
+			sb.WriteString(turtleString(object.Literal))
+
+			if object.Lang != "" {
+				sb.WriteString("@" + object.Lang)
+			} else if object.Datatype != "" {
+				sb.WriteString("^^" + object.Datatype)
+			}
+		}
+	}
+}
+
+// turtleIRI() writes an IRIREF, percent-encoding the characters it
+// cannot contain.  Turtle's \u escapes would stand for the characters
+// themselves and so still make an invalid IRI.
+func turtleIRI(val string) string {
+	var sb strings.Builder
+
+	sb.WriteByte('<')
+
+	for i := 0; i < len(val); i++ {
+		if c := val[i]; c <= ' ' || strings.IndexByte("<>\"{}|^`\\", c) >= 0 {
+			fmt.Fprintf(&sb, "%%%02X", c)
+			continue
+		}
+
+		sb.WriteByte(val[i])
+	}
+
+	sb.WriteByte('>')
+
+	return sb.String()
+}
+
+var turtleEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\t", `\t`,
+)
+
+func turtleString(val string) string {
+	return `"` + turtleEscaper.Replace(val) + `"`
+}
@@ -0,0 +1,443 @@
+package simplenuds
+
+import (
+	"bytes"
+	"embed"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// The bundled schemas.  schema/nuds.xsd imports the others.
+//
+//go:embed schema/*.xsd
+var schemaFS embed.FS
+
+var (
+	nudsSchema     *xsdSchema
+	nudsSchemaErr  error
+	nudsSchemaOnce sync.Once
+)
+
+// A Violation is a place where a document does not follow the schema.
+type Violation struct {
+	// Location of the element or attribute, e.g.
+	// /nuds/control/maintenanceHistory/maintenanceEvent[2]/eventDateTime/@standardDateTime
+	Path string
+
+	// The rule that was broken
+	Rule string
+}
+
+func (violation Violation) String() string {
+	return violation.Path + ": " + violation.Rule
+}
+
+// SchemaReport is the result of checking a document against the bundled
+// schemas.  They describe what simplenuds writes, in the order it writes
+// it, rather than the published NUDS, METS and XLink schemas, so a
+// document that passes is not necessarily valid NUDS, and one that uses
+// elements outside them is not necessarily invalid.
+type SchemaReport struct {
+	// Places that break a rule of the bundled schemas
+	Violations []Violation
+
+	// Elements and attributes the bundled schemas do not describe.  Their
+	// contents are not checked.
+	Unsupported []Violation
+}
+
+// OK() is true if there are no violations
+func (report *SchemaReport) OK() bool {
+	return len(report.Violations) == 0
+}
+
+// CheckSchema() checks a record against the bundled schemas
+func CheckSchema(nuds *NUDS) (*SchemaReport, error) {
+	data, err := xml.Marshal(nuds)
+	if err != nil {
+		return nil, err
+	}
+
+	return CheckSchemaXML(bytes.NewReader(data))
+}
+
+// CheckSchemaXML() checks a NUDS document against the bundled schemas.
+// The error is for documents that cannot be read.
+func CheckSchemaXML(r io.Reader) (*SchemaReport, error) {
+	nudsSchemaOnce.Do(func() {
+		nudsSchema, nudsSchemaErr = loadSchema(schemaFS, "schema/nuds.xsd")
+	})
+
+	if nudsSchemaErr != nil {
+		return nil, fmt.Errorf("loading NUDS schema: %w", nudsSchemaErr)
+	}
+
+	root, err := readInstance(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return nudsSchema.check(root), nil
+}
+
+// instanceNode is an element of the document being checked
+type instanceNode struct {
+	Name     xml.Name
+	Attr     []xml.Attr
+	Children []*instanceNode
+	Text     strings.Builder
+	Path     string
+}
+
+func readInstance(r io.Reader) (*instanceNode, error) {
+	decoder := xml.NewDecoder(r)
+
+	var stack []*instanceNode
+
+	var root *instanceNode
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &instanceNode{Name: t.Name, Attr: t.Attr}
+
+			if len(stack) == 0 {
+				root = node
+				node.Path = "/" + t.Name.Local
+			} else {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, node)
+				node.Path = parent.Path + "/" + t.Name.Local
+
+				// Number repeated siblings, XPath style
+				count := 0
+
+				for _, sibling := range parent.Children {
+					if sibling.Name == t.Name {
+						count++
+					}
+				}
+
+				if count > 1 {
+					node.Path += fmt.Sprintf("[%d]", count)
+				}
+			}
+
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].Text.Write(t)
+			}
+		}
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("empty document")
+	}
+
+	return root, nil
+}
+
+func (schema *xsdSchema) check(root *instanceNode) *SchemaReport {
+	report := &SchemaReport{}
+
+	decl, ok := schema.elements[root.Name]
+	if !ok {
+		report.Violations = append(report.Violations, Violation{
+			Path: root.Path,
+			Rule: fmt.Sprintf("no declaration for root element {%s}%s", root.Name.Space, root.Name.Local),
+		})
+
+		return report
+	}
+
+	checkElement(schema, root, decl, report)
+
+	return report
+}
+
+func checkElement(schema *xsdSchema, node *instanceNode, decl *elementDecl, report *SchemaReport) {
+	def := decl.Type
+
+	violation := func(path string, format string, args ...interface{}) {
+		report.Violations = append(report.Violations, Violation{Path: path, Rule: fmt.Sprintf(format, args...)})
+	}
+
+	unsupported := func(path string, format string, args ...interface{}) {
+		report.Unsupported = append(report.Unsupported, Violation{Path: path, Rule: fmt.Sprintf(format, args...)})
+	}
+
+	if def.Simple {
+		for _, attr := range node.Attr {
+			if !ignoredAttr(attr.Name) {
+				unsupported(node.Path+"/@"+attrPrefix(attr.Name)+attr.Name.Local, "attribute not in the bundled schemas")
+			}
+		}
+
+		if len(node.Children) > 0 {
+			violation(node.Children[0].Path, "element not allowed in simple content")
+		}
+
+		if msg := def.check(node.Text.String()); msg != "" {
+			violation(node.Path, msg)
+		}
+
+		return
+	}
+
+	checkAttributes(node, def, violation, unsupported)
+
+	if def.SimpleContent != nil {
+		if len(node.Children) > 0 {
+			violation(node.Children[0].Path, "element not allowed in simple content")
+		}
+
+		if msg := def.SimpleContent.check(node.Text.String()); msg != "" {
+			violation(node.Path, msg)
+		}
+
+		return
+	}
+
+	if !def.Mixed && strings.TrimSpace(node.Text.String()) != "" {
+		violation(node.Path, "text not allowed in element-only content")
+	}
+
+	decls := map[xml.Name]*elementDecl{}
+	wildcard := false
+
+	if def.Content != nil {
+		def.Content.elementDecls(decls)
+		wildcard = def.Content.hasWildcard()
+	}
+
+	// Elements the schemas know nothing about are set aside, rather than
+	// reported as not allowed, since they may be NUDS outside the subset
+	children := make([]*instanceNode, 0, len(node.Children))
+
+	for _, child := range node.Children {
+		_, local := decls[child.Name]
+		_, global := schema.elements[child.Name]
+
+		if !local && !global && !wildcard {
+			unsupported(child.Path, "element not in the bundled schemas")
+			continue
+		}
+
+		children = append(children, child)
+	}
+
+	names := make([]xml.Name, len(children))
+	for i, child := range children {
+		names[i] = child.Name
+	}
+
+	ends := map[int]bool{0: true}
+	if def.Content != nil {
+		ends = def.Content.match(names, ends)
+	}
+
+	if !ends[len(names)] {
+		furthest := 0
+		for end := range ends {
+			if end > furthest {
+				furthest = end
+			}
+		}
+
+		if furthest < len(names) {
+			violation(children[furthest].Path, "element <%s> not allowed here", names[furthest].Local)
+		} else {
+			violation(node.Path, "content is incomplete; a required element is missing")
+		}
+
+		// Only check children we recognized
+		names = names[:furthest]
+	}
+
+	for i := range names {
+		child := children[i]
+
+		childDecl, ok := decls[child.Name]
+		if !ok {
+			// Matched a wildcard; check if we know the element
+			childDecl, ok = schema.elements[child.Name]
+			if !ok {
+				continue
+			}
+		}
+
+		checkElement(schema, child, childDecl, report)
+	}
+}
+
+func checkAttributes(node *instanceNode, def *typeDef, violation, unsupported func(string, string, ...interface{})) {
+	seen := map[xml.Name]bool{}
+
+	for _, attr := range node.Attr {
+		if ignoredAttr(attr.Name) {
+			continue
+		}
+
+		seen[attr.Name] = true
+		path := node.Path + "/@" + attrPrefix(attr.Name) + attr.Name.Local
+
+		var use *attrUse
+
+		for i := range def.Attrs {
+			if def.Attrs[i].Decl.Name == attr.Name {
+				use = &def.Attrs[i]
+				break
+			}
+		}
+
+		if use == nil {
+			if !def.AnyAttribute {
+				unsupported(path, "attribute not in the bundled schemas")
+			}
+
+			continue
+		}
+
+		if msg := use.Decl.Type.check(attr.Value); msg != "" {
+			violation(path, msg)
+		}
+	}
+
+	for _, use := range def.Attrs {
+		if use.Required && !seen[use.Decl.Name] {
+			violation(node.Path, "missing required attribute %s%s", attrPrefix(use.Decl.Name), use.Decl.Name.Local)
+		}
+	}
+}
+
+// ignoredAttr() is true for namespace declarations and xsi: attributes
+func ignoredAttr(name xml.Name) bool {
+	return name.Space == "xmlns" ||
+		(name.Space == "" && name.Local == "xmlns") ||
+		name.Space == NamespaceXSI
+}
+
+// attrPrefix() gives the conventional prefix of a qualified attribute
+func attrPrefix(name xml.Name) string {
+	if prefix, ok := canonicalPrefixes[name.Space]; ok && prefix != "" {
+		return prefix + ":"
+	}
+
+	return ""
+}
+
+// match() returns every position in names that the particle can end at,
+// having started at any of the positions in starts.
+func (p *particle) match(names []xml.Name, starts map[int]bool) map[int]bool {
+	result := map[int]bool{}
+
+	if p.Min == 0 {
+		for start := range starts {
+			result[start] = true
+		}
+	}
+
+	current := starts
+
+	for count := 1; p.Max == unbounded || count <= p.Max; count++ {
+		next := p.matchOnce(names, current)
+
+		// Stop when an iteration consumes nothing new
+		progressed := false
+
+		for end := range next {
+			if !current[end] {
+				progressed = true
+			}
+		}
+
+		if count >= p.Min {
+			for end := range next {
+				result[end] = true
+			}
+		}
+
+		if len(next) == 0 || (!progressed && count >= p.Min) {
+			break
+		}
+
+		current = next
+	}
+
+	return result
+}
+
+func (p *particle) matchOnce(names []xml.Name, starts map[int]bool) map[int]bool {
+	next := map[int]bool{}
+
+	switch p.Kind {
+	case particleElement:
+		for start := range starts {
+			if start < len(names) && names[start] == p.Element.Name {
+				next[start+1] = true
+			}
+		}
+	case particleAny:
+		for start := range starts {
+			if start < len(names) {
+				next[start+1] = true
+			}
+		}
+	case particleSequence:
+		current := starts
+		for _, child := range p.Children {
+			current = child.match(names, current)
+		}
+
+		next = current
+	case particleChoice:
+		for _, child := range p.Children {
+			for end := range child.match(names, starts) {
+				next[end] = true
+			}
+		}
+	}
+
+	return next
+}
+
+// hasWildcard() is true if the content model has an <xs:any>
+func (p *particle) hasWildcard() bool {
+	if p.Kind == particleAny {
+		return true
+	}
+
+	for _, child := range p.Children {
+		if child.hasWildcard() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// elementDecls() collects the element declarations in a content model
+func (p *particle) elementDecls(decls map[xml.Name]*elementDecl) {
+	if p.Kind == particleElement {
+		decls[p.Element.Name] = p.Element
+	}
+
+	for _, child := range p.Children {
+		child.elementDecls(decls)
+	}
+}
@@ -0,0 +1,173 @@
+package simplenuds
+
+import (
+	"encoding/xml"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckSchemaValid(t *testing.T) {
+	for _, file := range []string{
+		"../converter/testdata/nuds264199.xml.golden",
+		"testdata/1922.999.73.xml",
+		"testdata/prefixes.xml",
+	} {
+		f, err := os.Open(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		report, err := CheckSchemaXML(f)
+		if err != nil || len(report.Violations) != 0 || len(report.Unsupported) != 0 {
+			t.Errorf("%s: %v %+v", file, err, report)
+		}
+
+		f.Close()
+	}
+
+	nuds := NewNUDS("physical", time.Date(2021, 1, 5, 10, 0, 0, 0, time.UTC))
+	nuds.Control.RecordID = "1"
+	nuds.DescMeta.DefaultTitle()[0] = Title{Lang: "en", Value: "Drachm"}
+
+	if report, err := CheckSchema(&nuds); err != nil || !report.OK() {
+		t.Errorf("NewNUDS record: %v %+v", err, report)
+	}
+}
+
+func TestCheckSchemaViolations(t *testing.T) {
+	doc := `<nuds xmlns="http://nomisma.org/nuds" xmlns:xlink="http://www.w3.org/1999/xlink" recordType="imaginary">
+	<control>
+		<publicationStatus>inProcess</publicationStatus>
+		<maintenanceStatus>derived</maintenanceStatus>
+		<maintenanceAgency><agencyName>Zeno.ru</agencyName></maintenanceAgency>
+		<maintenanceHistory>
+			<maintenanceEvent>
+				<eventType>derived</eventType>
+				<eventDateTime standardDateTime="2020-07-31T13:12:46-05:00">31 Jul 2020</eventDateTime>
+				<agentType>machine</agentType>
+				<agent>csv-nuds</agent>
+			</maintenanceEvent>
+			<maintenanceEvent>
+				<eventType>created</eventType>
+				<eventDateTime standardDateTime="13 Dec 20 11:55:36 +0300">13 Dec 20</eventDateTime>
+				<agentType>robot</agentType>
+				<agent>Ombo</agent>
+			</maintenanceEvent>
+		</maintenanceHistory>
+		<rightsStmt/>
+	</control>
+	<descMeta>
+		<title>Drachm</title>
+		<typeDesc>
+			<material xlink:type="complex">Silver</material>
+			<mint>AY</mint>
+		</typeDesc>
+		<physDesc>
+			<measurementsSet>
+				<weight units="g">3,7</weight>
+			</measurementsSet>
+		</physDesc>
+	</descMeta>
+</nuds>`
+
+	report, err := CheckSchemaXML(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		`/nuds/@recordType: "imaginary" is not one of conceptual, physical`,
+		`/nuds/control/publicationStatus: element <publicationStatus> not allowed here`,
+		`/nuds/descMeta/title: missing required attribute xml:lang`,
+		`/nuds/descMeta/typeDesc/material/@xlink:type: "complex" is not one of simple, extended, locator, arc, resource, title, none`,
+		`/nuds/descMeta/physDesc/measurementsSet/weight: "3,7" is not a valid xs:decimal`,
+	}
+
+	got := map[string]bool{}
+	for _, violation := range report.Violations {
+		got[violation.String()] = true
+	}
+
+	for _, w := range want {
+		if !got[w] {
+			t.Errorf("missing violation %s", w)
+		}
+	}
+
+	if len(report.Violations) != len(want) {
+		t.Errorf("got %d violations, want %d:\n%v", len(report.Violations), len(want), report.Violations)
+	}
+
+	// <mint> is not NUDS, but the bundled schemas cannot tell
+	wantUnsupported := []Violation{{Path: "/nuds/descMeta/typeDesc/mint", Rule: "element not in the bundled schemas"}}
+	if !reflect.DeepEqual(report.Unsupported, wantUnsupported) {
+		t.Errorf("got unsupported %v, want %v", report.Unsupported, wantUnsupported)
+	}
+
+	// Violations inside the unexpected control content are not reported,
+	// so check maintenanceEvent rules on their own.
+	doc = strings.Replace(doc, "<control>", "<control><recordId>1</recordId>", 1)
+
+	report, err = CheckSchemaXML(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got = map[string]bool{}
+	for _, violation := range report.Violations {
+		got[violation.String()] = true
+	}
+
+	for _, w := range []string{
+		`/nuds/control/maintenanceHistory/maintenanceEvent[2]/eventDateTime/@standardDateTime: ` +
+			`"13 Dec 20 11:55:36 +0300" is not valid for any member of the union`,
+		`/nuds/control/maintenanceHistory/maintenanceEvent[2]/agentType: "robot" is not one of human, machine`,
+	} {
+		if !got[w] {
+			t.Errorf("missing violation %s in\n%v", w, report.Violations)
+		}
+	}
+}
+
+// Numishare records may use NUDS the bundled schemas do not describe
+func TestCheckSchemaUnsupported(t *testing.T) {
+	f, err := os.Open("testdata/1922.999.73.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	nuds, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := xml.Marshal(nuds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc := strings.Replace(string(data), "<physDesc>",
+		`<physDesc><conservationState><grade>VF</grade></conservationState>`, 1)
+	doc = strings.Replace(doc, "<typeDesc>", `<typeDesc certainty="probable">`, 1)
+
+	report, err := CheckSchemaXML(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !report.OK() {
+		t.Errorf("got violations %v", report.Violations)
+	}
+
+	want := []Violation{
+		{Path: "/nuds/descMeta/typeDesc/@certainty", Rule: "attribute not in the bundled schemas"},
+		{Path: "/nuds/descMeta/physDesc/conservationState", Rule: "element not in the bundled schemas"},
+	}
+	if !reflect.DeepEqual(report.Unsupported, want) {
+		t.Errorf("got unsupported %v, want %v", report.Unsupported, want)
+	}
+}
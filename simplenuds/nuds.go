@@ -2,6 +2,7 @@ package simplenuds
 
 import (
 	"encoding/xml"
+	"strconv"
 	"time"
 )
 
@@ -21,6 +22,7 @@ type NUDS struct {
 	XS_NS          string `xml:"xmlns:xs,attr"`    // nolint: golint,stylecheck
 	XLINK_NS       string `xml:"xmlns:xlink,attr"` // nolint: golint,stylecheck
 	XSI_NS         string `xml:"xmlns:xsi,attr"`   // nolint: golint,stylecheck
+	GML_NS         string `xml:"xmlns:gml,attr"`   // nolint: golint,stylecheck
 	SchemaLocation string `xml:"xsi:schemaLocation,attr"`
 
 	// The @recordType is a required attribute for the <nuds> root element.
@@ -39,8 +41,8 @@ type NUDS struct {
 type Control struct {
 	RecordID string `xml:"recordId"`
 
-	// TODO nolint:godox
 	// <xs:element maxOccurs="unbounded" minOccurs="0" ref="otherRecordId"/>
+	OtherRecordID []OtherRecordID `xml:"otherRecordId"`
 
 	// <xs:element ref="publicationStatus"/>
 	PublicationStatus PublicationStatus `xml:"publicationStatus"`
@@ -58,10 +60,41 @@ type Control struct {
 	RightsStmt RightsStmt `xml:"rightsStmt"`
 
 	// <xs:element maxOccurs="unbounded" minOccurs="0" ref="semanticDeclaration"/>
+	SemanticDeclaration []SemanticDeclaration `xml:"semanticDeclaration"`
+
 	// <xs:attribute ref="xml:id"/>
 	// <xs:attribute ref="xml:lang"/>
 }
 
+// Another identifier of the object, such as the URI of the same coin in
+// another database.  The semantic is a property whose prefix is declared
+// in a <semanticDeclaration>, e.g.
+//
+//	<otherRecordId semantic="dcterms:source">https://www.zeno.ru/showphoto.php?photo=58627</otherRecordId>
+type OtherRecordID struct {
+	// <xs:attribute name="semantic"/>
+	Semantic string `xml:"semantic,attr,omitempty"`
+
+	// <xs:attribute name="localType"/>
+	LocalType string `xml:"localType,attr,omitempty"`
+
+	Value string `xml:",chardata"`
+}
+
+// Declares the namespace of a prefix used in a semantic attribute, e.g.
+//
+//	<semanticDeclaration>
+//	  <prefix>dcterms</prefix>
+//	  <namespace>http://purl.org/dc/terms/</namespace>
+//	</semanticDeclaration>
+type SemanticDeclaration struct {
+	// <xs:element ref="prefix"/>
+	Prefix string `xml:"prefix"`
+
+	// <xs:element ref="namespace"/>
+	Namespace string `xml:"namespace"`
+}
+
 // The Descriptive Metadata element is one of two required elements within <nuds>.
 // It is the container for all descriptive metadata containers for an object or typology.
 // <typeDesc> is the only required child element.
@@ -73,8 +106,6 @@ type DescMeta struct {
 	// TODO nolint:godox
 	//<xs:element minOccurs="0" ref="subjectSet"/>
 	//<xs:element minOccurs="0" ref="undertypeDesc"/>
-	//<xs:element minOccurs="0" ref="findspotDesc"/>
-	//<xs:element minOccurs="0" ref="refDesc"/>
 
 	//<xs:element minOccurs="0" ref="descriptionSet"/>
 	DescriptionSet []DescriptionSet `xml:"descriptionSet"`
@@ -88,8 +119,14 @@ type DescMeta struct {
 	//<xs:element minOccurs="0" ref="physDesc"/>
 	PhysDesc *PhysDesc `xml:"physDesc"`
 
+	//<xs:element minOccurs="0" ref="refDesc"/>
+	RefDesc *RefDesc `xml:"refDesc"`
+
 	//<xs:element minOccurs="0" ref="adminDesc"/>
 	AdminDesc *AdminDesc `xml:"adminDesc"`
+
+	//<xs:element minOccurs="0" ref="findspotDesc"/>
+	FindspotDesc *FindspotDesc `xml:"findspotDesc"`
 }
 
 // Object title. It may be repeated if the title is to be available in numerous languages
@@ -116,6 +153,7 @@ type DescriptionSet struct {
 type Description struct {
 	// <xs:attribute ref="xml:id"/>
 	// <xs:attribute ref="xml:lang"/>
+	Lang string `xml:"xml:lang,attr,omitempty"`
 
 	// <xs:attributeGroup ref="m.default"/>
 	Value string `xml:",chardata"`
@@ -144,12 +182,23 @@ type Notes []Note
 // object or a coin type. The <typeDesc> is the only required top-level
 // descriptive element within <descMeta>.
 type TypeDesc struct {
+	// A physical object may link to its coin type, e.g.
+	// <typeDesc xlink:type="simple" xlink:href="http://numismatics.org/ocre/id/ric.1(2).aug.1a">
+	// <xs:attributeGroup ref="xlink:simpleLink"/>
+	Type string `xml:"xlink:type,attr,omitempty"`
+	HRef string `xml:"xlink:href,attr,omitempty"`
+
 	// <xs:element minOccurs="0" ref="objectType"/>
+
 	// <xs:choice>
 	// <xs:element minOccurs="0" ref="date"/>
+	Date *Date `xml:"date"`
 	// <xs:element minOccurs="0" ref="dateRange"/>
+	DateRange *DateRange `xml:"dateRange"`
 	// </xs:choice>
+
 	// <xs:element minOccurs="0" maxOccurs="1" ref="dateOnObject"/>
+	DateOnObject *DateOnObject `xml:"dateOnObject"`
 
 	// <xs:element minOccurs="0" maxOccurs="unbounded" ref="denomination"/>
 	Denomination []Denomination `xml:"denomination"`
@@ -160,13 +209,25 @@ type TypeDesc struct {
 	Material []Material `xml:"material"`
 
 	// <xs:element minOccurs="0" maxOccurs="1" ref="shape"/>
+
 	// <xs:element minOccurs="0" ref="authority"/>
+	Authority *Authority `xml:"authority"`
+
 	// <xs:element minOccurs="0" ref="geographic"/>
+	Geographic *Geographic `xml:"geographic"`
+
 	// <xs:element minOccurs="0" ref="obverse"/>
+	Obverse *Side `xml:"obverse"`
 	// <xs:element minOccurs="0" ref="reverse"/>
+	Reverse *Side `xml:"reverse"`
+
 	// <xs:element minOccurs="0" ref="edge"/>
 	// <xs:element minOccurs="0" ref="weightStandard"/>
+
 	// <xs:element minOccurs="0" ref="typeSeries"/>
+	TypeSeries *TypeSeries `xml:"typeSeries"`
+	// <xs:element minOccurs="0" ref="typeSeriesItem"/>
+	TypeSeriesItem *TypeSeriesItem `xml:"typeSeriesItem"`
 }
 
 // The Physical Description element of <descMeta> is a container for the physical characteristics
@@ -174,7 +235,10 @@ type TypeDesc struct {
 type PhysDesc struct {
 	// <xs:element minOccurs="0" maxOccurs="unbounded" ref="authenticity"/>
 	// <xs:element minOccurs="0" maxOccurs="unbounded" ref="originalIntendedUse"/>
+
 	// <xs:element minOccurs="0" maxOccurs="unbounded" ref="peculiarityOfProduction"/>
+	PeculiarityOfProduction []PeculiarityOfProduction `xml:"peculiarityOfProduction"`
+
 	// <xs:element minOccurs="0" maxOccurs="1" ref="axis"/>
 	// <xs:element minOccurs="0" maxOccurs="1" ref="channelOrientation"/>
 	// <xs:element minOccurs="0" maxOccurs="unbounded" ref="chemicalAnalysis"/>
@@ -192,6 +256,17 @@ type PhysDesc struct {
 	// <xs:element minOccurs="0" maxOccurs="1" ref="watermark"/>
 }
 
+// A peculiarity of the production of an object, such as plating
+// or a fourrée core, e.g. <peculiarityOfProduction>silver washed</peculiarityOfProduction>
+type PeculiarityOfProduction struct {
+	// <xs:attributeGroup ref="m.default"/>
+	// <xs:attributeGroup ref="xlink:simpleLink"/>
+	HRef string `xml:"xlink:href,attr,omitempty"`
+	Type string `xml:"xlink:type,attr,omitempty"`
+
+	Value string `xml:",chardata"`
+}
+
 // The <measurementsSet> is a container for physical measurments of an object.
 type MeasurementsSet struct {
 	// <xs:element minOccurs="0" ref="diameter"/>
@@ -228,22 +303,203 @@ type Weight struct {
 	Value string `xml:",chardata"`
 }
 
+// A date, with the machine-readable form in @standardDate.  Years are
+// written as xs:gYear, so 591 AD is "0591" and 100 BC is "-0100".
+// For example <date standardDate="0622">622</date>
+type Date struct {
+	// <xs:attribute name="standardDate"/>
+	StandardDate string `xml:"standardDate,attr,omitempty"`
+
+	// <xs:attribute name="certainty"/>
+	Certainty string `xml:"certainty,attr,omitempty"`
+
+	Value string `xml:",chardata"`
+}
+
+// A range of dates, such as the reign of a ruler.
+type DateRange struct {
+	// <xs:element ref="fromDate"/>
+	FromDate Date `xml:"fromDate"`
+
+	// <xs:element ref="toDate"/>
+	ToDate Date `xml:"toDate"`
+}
+
+// The date as it appears on the object, for example a regnal year,
+// which may be in a different calendar from <date>.
+type DateOnObject struct {
+	// <xs:attribute name="calendar"/>
+	Calendar string `xml:"calendar,attr,omitempty"`
+
+	Value string `xml:",chardata"`
+}
+
 // The <denomination>, usually defined by a Nomisma URI by means of XLink attributes.
-// <xs:attributeGroup ref="m.default"/>
-// <xs:attributeGroup ref="xlink:simpleLink"/>
-type Denomination string
+// For example <denomination xlink:href="http://nomisma.org/id/drachm" xlink:type="simple">Drachm</denomination>
+type Denomination struct {
+	// <xs:attributeGroup ref="m.default"/>
+	// <xs:attributeGroup ref="xlink:simpleLink"/>
+	HRef string `xml:"xlink:href,attr,omitempty"`
+	Type string `xml:"xlink:type,attr,omitempty"`
+
+	Value string `xml:",chardata"`
+}
 
 // The <material> (e.g., silver), usually defined by a Nomisma URI by means of XLink attributes.
 // For example <material xlink:href="http://nomisma.org/id/ar" xlink:type="simple">Silver</material>
 type Material struct {
 	// <xs:attributeGroup ref="m.default"/>
 	// <xs:attributeGroup ref="xlink:simpleLink"/>
-	HRef string `xml:"href,attr,omitempty"`
-	Type string `xml:"type,attr,omitempty"`
+	HRef string `xml:"xlink:href,attr,omitempty"`
+	Type string `xml:"xlink:type,attr,omitempty"`
 
 	Text string `xml:",chardata"`
 }
 
+// The <geographic> element is a container for geographic names related to
+// the production of a coin type, such as the mint or region.
+type Geographic struct {
+	// <xs:element maxOccurs="unbounded" ref="geogname"/>
+	Geogname []Geogname `xml:"geogname"`
+}
+
+// A geographic name, such as a mint, region or locality.  The role is
+// carried in xlink:role, for example
+// <geogname xlink:role="mint" xlink:type="simple" xlink:href="http://nomisma.org/id/merv">Merv</geogname>
+type Geogname struct {
+	// <xs:attributeGroup ref="m.default"/>
+	// <xs:attributeGroup ref="xlink:simpleLink"/>
+	Role string `xml:"xlink:role,attr,omitempty"`
+	Type string `xml:"xlink:type,attr,omitempty"`
+	Href string `xml:"xlink:href,attr,omitempty"`
+
+	Value string `xml:",chardata"`
+}
+
+// The <authority> names who issued a coin: the ruler in whose name it was
+// struck, the official responsible, and the dynasty or state, e.g.
+//
+//	<authority>
+//	  <persname xlink:role="authority" xlink:type="simple">Khusru II</persname>
+//	  <famname xlink:role="dynasty" xlink:type="simple">Sasanian</famname>
+//	</authority>
+type Authority struct {
+	// <xs:choice maxOccurs="unbounded">
+	// <xs:element ref="persname"/>
+	Persname []Persname `xml:"persname"`
+	// <xs:element ref="corpname"/>
+	Corpname []Corpname `xml:"corpname"`
+	// <xs:element ref="famname"/>
+	Famname []Famname `xml:"famname"`
+	// </xs:choice>
+}
+
+// A corporate name, such as a state or city that issued coins
+type Corpname struct {
+	// <xs:attributeGroup ref="m.default"/>
+	// <xs:attributeGroup ref="xlink:simpleLink"/>
+	Role string `xml:"xlink:role,attr,omitempty"`
+	Type string `xml:"xlink:type,attr,omitempty"`
+	Href string `xml:"xlink:href,attr,omitempty"`
+
+	Value string `xml:",chardata"`
+}
+
+// A family name, such as a dynasty
+type Famname struct {
+	// <xs:attributeGroup ref="m.default"/>
+	// <xs:attributeGroup ref="xlink:simpleLink"/>
+	Role string `xml:"xlink:role,attr,omitempty"`
+	Type string `xml:"xlink:type,attr,omitempty"`
+	Href string `xml:"xlink:href,attr,omitempty"`
+
+	Value string `xml:",chardata"`
+}
+
+// <obverse> and <reverse> describe one side of a coin: its legend, the
+// type (what is depicted), the person portrayed and any symbols, e.g.
+//
+//	<obverse>
+//	  <legend>GDH hwslwb</legend>
+//	  <type><description xml:lang="en">Bust of Khusru II right</description></type>
+//	  <persname xlink:role="portrait">Khusru II</persname>
+//	</obverse>
+type Side struct {
+	// <xs:choice maxOccurs="unbounded">
+	// <xs:element ref="legend"/>
+	Legend []Legend `xml:"legend"`
+	// <xs:element ref="type"/>
+	Type *SideType `xml:"type"`
+	// <xs:element ref="persname"/>
+	Persname []Persname `xml:"persname"`
+	// <xs:element ref="symbol"/>
+	Symbol []Symbol `xml:"symbol"`
+	// </xs:choice>
+}
+
+// The <type> of a side holds descriptions of what is depicted, one per language.
+type SideType struct {
+	// <xs:element maxOccurs="unbounded" ref="description"/>
+	Description []Description `xml:"description"`
+}
+
+// The <legend> is the inscription on a side.  It is either plain text or,
+// for legends with several lines, a TEI edition, e.g.
+//
+//	<legend scriptPhysical="Inscriptional Pahlavi">
+//	  <tei:div type="edition"><tei:ab>...</tei:ab><tei:ab>...</tei:ab></tei:div>
+//	</legend>
+type Legend struct {
+	// <xs:attributeGroup ref="m.default"/>
+	Lang string `xml:"xml:lang,attr,omitempty"`
+	// The script the legend is written in, e.g. "Greek" or "Inscriptional Pahlavi"
+	ScriptPhysical string `xml:"scriptPhysical,attr,omitempty"`
+
+	Value string `xml:",chardata"`
+
+	// <xs:element minOccurs="0" ref="tei:div"/>
+	TEI *TEIDiv `xml:"tei:div"`
+}
+
+// A TEI division.  NUDS legends use <tei:div type="edition">.
+type TEIDiv struct {
+	Type string `xml:"type,attr,omitempty"`
+
+	// <xs:element maxOccurs="unbounded" ref="tei:ab"/>
+	Ab []TEIAb `xml:"tei:ab"`
+}
+
+// A TEI anonymous block, one line of a legend.
+type TEIAb struct {
+	Value string `xml:",chardata"`
+}
+
+// A personal name, such as the ruler portrayed on a side or the authority
+// who issued a coin.  xlink:role is "portrait", "authority" or "issuer".
+// <persname xlink:role="portrait">Khusru II</persname>
+type Persname struct {
+	// <xs:attributeGroup ref="m.default"/>
+	// <xs:attributeGroup ref="xlink:simpleLink"/>
+	Role string `xml:"xlink:role,attr,omitempty"`
+	Type string `xml:"xlink:type,attr,omitempty"`
+	Href string `xml:"xlink:href,attr,omitempty"`
+
+	Value string `xml:",chardata"`
+}
+
+// A symbol, monogram or control mark on a side, e.g. the star and crescent
+// beside a fire altar.
+type Symbol struct {
+	// <xs:attributeGroup ref="m.default"/>
+	// <xs:attributeGroup ref="xlink:simpleLink"/>
+	// <xs:attributeGroup ref="nuds_a.localType"/>
+	LocalType string `xml:"localType,attr,omitempty"`
+	Type      string `xml:"xlink:type,attr,omitempty"`
+	Href      string `xml:"xlink:href,attr,omitempty"`
+
+	Value string `xml:",chardata"`
+}
+
 type PublicationStatus struct {
 	// <xs:enumeration value="inProcess"/>
 	// <xs:enumeration value="approved"/>
@@ -335,7 +591,8 @@ type EventDateTime struct {
 	// For example <eventDateTime standardDateTime="2020-07-31T13:12:46-05:00">Fri, 31 Jul 2020</eventDateTime>
 
 	// The date or date and time represented in a standard form for computer processing.
-	StandardDateTime string `xml:"standardDateTime,attr"`
+	// This is an xs:dateTime, such as 2020-07-31T13:12:46-05:00.
+	StandardDateTime string `xml:"standardDateTime,attr,omitempty"`
 
 	Value string `xml:",chardata"`
 }
@@ -381,33 +638,255 @@ type License struct {
 
 type DigRep struct {
 	// XMLName  xml.Name `xml:"control"`
+	AmdSec  *AmdSec `xml:"mets:amdSec,omitempty"`
 	FileSec FileSec `xml:"mets:fileSec"`
 }
 
+// The Reference Description, <refDesc>, lists published references to
+// the object or its type, such as catalog numbers.
+type RefDesc struct {
+	// <xs:element maxOccurs="unbounded" ref="reference"/>
+	Reference []Reference `xml:"reference"`
+}
+
+// A <reference> is a citation.  It is free text, or a TEI title and
+// number, and may link to the type in an online corpus, e.g.
+//
+//	<reference xlink:type="simple" xlink:href="http://numismatics.org/crro/id/rrc-44.5">
+//	  <tei:title key="http://nomisma.org/id/rrc">RRC</tei:title><tei:idno>44/5</tei:idno>
+//	</reference>
+type Reference struct {
+	// <xs:attributeGroup ref="m.default"/>
+	// <xs:attributeGroup ref="xlink:simpleLink"/>
+	Type string `xml:"xlink:type,attr,omitempty"`
+	Href string `xml:"xlink:href,attr,omitempty"`
+
+	Value string `xml:",chardata"`
+
+	// <xs:element minOccurs="0" ref="tei:title"/>
+	Title *TEITitle `xml:"tei:title"`
+	// <xs:element minOccurs="0" ref="tei:idno"/>
+	Idno *TEIIdno `xml:"tei:idno"`
+}
+
+// A TEI title, such as the abbreviation of a catalog.  The key may be the
+// URI of the catalog.
+type TEITitle struct {
+	Key string `xml:"key,attr,omitempty"`
+
+	Value string `xml:",chardata"`
+}
+
+// A TEI identifying number, such as the number of a type within a catalog
+type TEIIdno struct {
+	Value string `xml:",chardata"`
+}
+
+// The type series, a catalog of coin types, that the object belongs to
+// <typeSeries xlink:type="simple" xlink:href="http://nomisma.org/id/rrc">Roman Republican Coinage</typeSeries>
+type TypeSeries struct {
+	// <xs:attributeGroup ref="m.default"/>
+	// <xs:attributeGroup ref="xlink:simpleLink"/>
+	Type string `xml:"xlink:type,attr,omitempty"`
+	Href string `xml:"xlink:href,attr,omitempty"`
+
+	Value string `xml:",chardata"`
+}
+
+// The type within the type series
+// <typeSeriesItem xlink:type="simple" xlink:href="http://numismatics.org/crro/id/rrc-44.5">RRC 44/5</typeSeriesItem>
+type TypeSeriesItem struct {
+	// <xs:attributeGroup ref="m.default"/>
+	// <xs:attributeGroup ref="xlink:simpleLink"/>
+	Type string `xml:"xlink:type,attr,omitempty"`
+	Href string `xml:"xlink:href,attr,omitempty"`
+
+	Value string `xml:",chardata"`
+}
+
+// The Findspot Description, <findspotDesc>, records where an object was
+// found and the hoard it was part of, e.g.
+//
+//	<findspotDesc>
+//	  <findspot>
+//	    <geogname xlink:role="findspot" xlink:type="simple">Nineveh</geogname>
+//	    <gml:location>
+//	      <gml:Point srsName="http://www.opengis.net/def/crs/EPSG/0/4326"><gml:pos>36.36 43.15</gml:pos></gml:Point>
+//	    </gml:location>
+//	  </findspot>
+//	  <hoard xlink:type="simple" xlink:href="http://coinhoards.org/id/igch1739">IGCH 1739</hoard>
+//	</findspotDesc>
+type FindspotDesc struct {
+	// <xs:element minOccurs="0" ref="findspot"/>
+	Findspot *Findspot `xml:"findspot"`
+
+	// <xs:element minOccurs="0" ref="hoard"/>
+	Hoard *Hoard `xml:"hoard"`
+}
+
+// The place an object was found
+type Findspot struct {
+	// <xs:element minOccurs="0" maxOccurs="unbounded" ref="geogname"/>
+	Geogname []Geogname `xml:"geogname"`
+
+	// <xs:element minOccurs="0" maxOccurs="unbounded" ref="description"/>
+	Description []Description `xml:"description"`
+
+	// <xs:element minOccurs="0" ref="gml:location"/>
+	Location *GMLLocation `xml:"gml:location"`
+}
+
+// A GML location, the coordinates of a findspot
+type GMLLocation struct {
+	// <xs:element ref="gml:Point"/>
+	Point GMLPoint `xml:"gml:Point"`
+}
+
+// A GML point.  With the srsName of WGS 84, EPSG:4326, the position is
+// the latitude and longitude in decimal degrees, separated by a space.
+type GMLPoint struct {
+	SrsName string `xml:"srsName,attr,omitempty"`
+
+	// <xs:element ref="gml:pos"/>
+	Pos string `xml:"gml:pos"`
+}
+
+// The hoard an object was part of, linked to a hoard database such as
+// Coin Hoards, http://coinhoards.org/
+type Hoard struct {
+	// <xs:attributeGroup ref="m.default"/>
+	// <xs:attributeGroup ref="xlink:simpleLink"/>
+	Type string `xml:"xlink:type,attr,omitempty"`
+	Href string `xml:"xlink:href,attr,omitempty"`
+
+	Value string `xml:",chardata"`
+}
+
 // The Administrative Description contains information pertaining to provenance,
 // accessioning, deaccessioning, and other administrative metadata. This element
-// should not be used in conceptual records.
+// should not be used in conceptual records, e.g.
+//
+//	<adminDesc>
+//	  <identifier>1922.999.73</identifier>
+//	  <department>Sasanian</department>
+//	  <collection xlink:type="simple">American Numismatic Society</collection>
+//	  <provenance>
+//	    <chronList>
+//	      <chronItem><date standardDate="1922">1922</date><acquiredFrom xlink:type="simple">Edward T. Newell</acquiredFrom></chronItem>
+//	    </chronList>
+//	  </provenance>
+//	</adminDesc>
 type AdminDesc struct {
-	// TODO nolint:godox
 	// <xs:element ref="identifier"/>
+	// The accession number
+	Identifier string `xml:"identifier,omitempty"`
+
 	// <xs:element minOccurs="0" maxOccurs="unbounded" ref="department"/>
+	Department []string `xml:"department"`
+
 	// <xs:element ref="collection"/>
+	Collection *Collection `xml:"collection"`
+
 	// <xs:element ref="repository"/>
+	Repository *Repository `xml:"repository"`
+
 	// <xs:element ref="physloc"/>
+	// Where the object is kept within the repository, e.g. a cabinet and tray
+	Physloc string `xml:"physloc,omitempty"`
+
 	// <xs:element ref="owner"/>
+	Owner *Owner `xml:"owner"`
+
+	// TODO nolint:godox
 	// <xs:element ref="display"/>
 	// <xs:element ref="appraisal"/>
+
 	// <xs:element ref="provenance"/>
+	Provenance *Provenance `xml:"provenance"`
 
 	// <xs:element ref="acknowledgment"/>
-	Acknowledgement Acknowlegement `xml:"acknowledgement"`
+	Acknowledgment []Acknowlegement `xml:"acknowledgment"`
+}
+
+// The collection the object belongs to
+type Collection struct {
+	// <xs:attributeGroup ref="m.default"/>
+	// <xs:attributeGroup ref="xlink:simpleLink"/>
+	Type string `xml:"xlink:type,attr,omitempty"`
+	Href string `xml:"xlink:href,attr,omitempty"`
+
+	Value string `xml:",chardata"`
+}
+
+// The institution that holds the object
+type Repository struct {
+	// <xs:attributeGroup ref="m.default"/>
+	// <xs:attributeGroup ref="xlink:simpleLink"/>
+	Type string `xml:"xlink:type,attr,omitempty"`
+	Href string `xml:"xlink:href,attr,omitempty"`
+
+	Value string `xml:",chardata"`
+}
+
+// The person or institution that owns the object, which may not be the
+// repository, e.g. for a loan
+type Owner struct {
+	// <xs:attributeGroup ref="m.default"/>
+	// <xs:attributeGroup ref="xlink:simpleLink"/>
+	Type string `xml:"xlink:type,attr,omitempty"`
+	Href string `xml:"xlink:href,attr,omitempty"`
+
+	Value string `xml:",chardata"`
+}
+
+// The history of ownership of the object
+type Provenance struct {
+	// <xs:element ref="chronList"/>
+	ChronList ChronList `xml:"chronList"`
+}
+
+// A chronological list of events, earliest first
+type ChronList struct {
+	// <xs:element maxOccurs="unbounded" ref="chronItem"/>
+	ChronItem []ChronItem `xml:"chronItem"`
 }
 
+// One event in the provenance, such as an acquisition
+type ChronItem struct {
+	// <xs:element minOccurs="0" ref="date"/>
+	Date *Date `xml:"date"`
+
+	// <xs:element minOccurs="0" ref="acquiredFrom"/>
+	AcquiredFrom *AcquiredFrom `xml:"acquiredFrom"`
+
+	// <xs:element minOccurs="0" ref="event"/>
+	// How the object changed hands, e.g. "purchase", "gift" or "bequest"
+	Event string `xml:"event,omitempty"`
+}
+
+// The person or institution from whom the object was acquired
+type AcquiredFrom struct {
+	// <xs:attributeGroup ref="m.default"/>
+	// <xs:attributeGroup ref="xlink:simpleLink"/>
+	Type string `xml:"xlink:type,attr,omitempty"`
+	Href string `xml:"xlink:href,attr,omitempty"`
+
+	Value string `xml:",chardata"`
+}
+
+// Credit to a person or institution, such as whoever reported the object,
+// linked to their page, e.g.
+//
+//	<acknowledgment xlink:type="simple" xlink:href="https://www.zeno.ru/member.php?uid=1503">viur</acknowledgment>
 type Acknowlegement struct {
 	// <xs:attribute ref="xml:id"/>
 	// <xs:attribute ref="xml:lang"/>
 	// <xs:attributeGroup ref="m.default"/>
 	// <xs:attributeGroup ref="xlink:simpleLink"/>
+	Type string `xml:"xlink:type,attr,omitempty"`
+	Href string `xml:"xlink:href,attr,omitempty"`
+
+	Value string `xml:",chardata"`
 }
 
 // See http://www.loc.gov/standards/mets/mets.xsd
@@ -452,6 +931,82 @@ type File struct {
 	// attribute value at the <fileGrp> level should pertain to all of the
 	// files in the <fileGrp>.
 	USE string `xml:"USE,attr,omitempty"`
+
+	// <xsd:attribute name="MIMETYPE" type="xsd:string" use="optional">
+	// MIMETYPE (string/O): The IANA MIME media type for the associated file
+	// or wrapped content. Some values for this attribute can be found on the
+	// IANA website.
+	MIMETYPE string `xml:"MIMETYPE,attr,omitempty"`
+
+	// <xsd:attribute name="SIZE" type="xsd:long" use="optional">
+	// SIZE (long/O): Specifies the size in bytes of the associated file or
+	// wrapped content.
+	SIZE int64 `xml:"SIZE,attr,omitempty"`
+
+	// <xsd:attribute name="CHECKSUM" type="xsd:string" use="optional">
+	// CHECKSUM (string/O): Provides a checksum value for the associated file
+	// or wrapped content.
+	CHECKSUM string `xml:"CHECKSUM,attr,omitempty"`
+
+	// <xsd:attribute name="CHECKSUMTYPE" use="optional">
+	// CHECKSUMTYPE (enumerated string/O): Specifies the checksum algorithm
+	// used to produce the value contained in the CHECKSUM attribute, e.g.
+	// "MD5" or "SHA-256".
+	CHECKSUMTYPE string `xml:"CHECKSUMTYPE,attr,omitempty"`
+
+	// <xsd:attribute name="ADMID" type="xsd:IDREFS" use="optional">
+	// ADMID (IDREFS/O): Contains the ID attribute values of the <techMD>
+	// elements in the <amdSec> that describe the file.
+	ADMID string `xml:"ADMID,attr,omitempty"`
+}
+
+// The administrative metadata section <amdSec> records information
+// about the files, such as the dimensions of images.
+type AmdSec struct {
+	TechMD []TechMD `xml:"mets:techMD"`
+}
+
+// A technical metadata element <techMD> describes a file, which refers to
+// it by its ID in ADMID.
+type TechMD struct {
+	ID     string `xml:"ID,attr"`
+	MdWrap MdWrap `xml:"mets:mdWrap"`
+}
+
+// A metadata wrapper element <mdWrap> holds metadata of the type MDTYPE,
+// e.g. "NISOIMG" for MIX.
+type MdWrap struct {
+	MDTYPE  string  `xml:"MDTYPE,attr"`
+	XMLData XMLData `xml:"mets:xmlData"`
+}
+
+type XMLData struct {
+	Mix *Mix `xml:"mix:mix,omitempty"`
+}
+
+// <mix:mix> is NISO Metadata for Images in XML (MIX), e.g.
+//
+//	<mix:mix xmlns:mix="http://www.loc.gov/mix/v20">
+//	  <mix:BasicImageInformation>
+//	    <mix:BasicImageCharacteristics>
+//	      <mix:imageWidth>1200</mix:imageWidth>
+//	      <mix:imageHeight>1180</mix:imageHeight>
+//	    </mix:BasicImageCharacteristics>
+//	  </mix:BasicImageInformation>
+//	</mix:mix>
+type Mix struct {
+	MIX_NS                string                `xml:"xmlns:mix,attr"` // nolint: golint,stylecheck
+	BasicImageInformation BasicImageInformation `xml:"mix:BasicImageInformation"`
+}
+
+type BasicImageInformation struct {
+	BasicImageCharacteristics BasicImageCharacteristics `xml:"mix:BasicImageCharacteristics"`
+}
+
+// Dimensions of an image in pixels
+type BasicImageCharacteristics struct {
+	ImageWidth  int `xml:"mix:imageWidth"`
+	ImageHeight int `xml:"mix:imageHeight"`
 }
 
 // The file location element <FLocat> provides a pointer to the location
@@ -473,6 +1028,36 @@ func (nuds *NUDS) DefaultDigRep() *DigRep {
 	return nuds.DigRep
 }
 
+// AppendTechMD() adds technical metadata about a file, giving it the next
+// ID, such as "techMD1", for the file's ADMID
+func (digRep *DigRep) AppendTechMD(techMD TechMD) string {
+	if digRep.AmdSec == nil {
+		digRep.AmdSec = &AmdSec{}
+	}
+
+	techMD.ID = "techMD" + strconv.Itoa(len(digRep.AmdSec.TechMD)+1)
+	digRep.AmdSec.TechMD = append(digRep.AmdSec.TechMD, techMD)
+
+	return techMD.ID
+}
+
+// DefaultFileGrp() finds the file group with the USE, such as "obverse",
+// adding it if there is none
+func (fileSec *FileSec) DefaultFileGrp(use string) *FileGrp {
+	for i := range fileSec.FileGrp {
+		if fileSec.FileGrp[i].USE == use {
+			return &fileSec.FileGrp[i]
+		}
+	}
+
+	fileSec.FileGrp = append(fileSec.FileGrp, FileGrp{
+		File: []File{},
+		USE:  use,
+	})
+
+	return &fileSec.FileGrp[len(fileSec.FileGrp)-1]
+}
+
 func (descMeta *DescMeta) DefaultPhysDesc() *PhysDesc {
 	if descMeta.PhysDesc == nil {
 		descMeta.PhysDesc = &PhysDesc{}
@@ -481,6 +1066,86 @@ func (descMeta *DescMeta) DefaultPhysDesc() *PhysDesc {
 	return descMeta.PhysDesc
 }
 
+func (descMeta *DescMeta) DefaultRefDesc() *RefDesc {
+	if descMeta.RefDesc == nil {
+		descMeta.RefDesc = &RefDesc{}
+	}
+
+	return descMeta.RefDesc
+}
+
+func (descMeta *DescMeta) DefaultAdminDesc() *AdminDesc {
+	if descMeta.AdminDesc == nil {
+		descMeta.AdminDesc = &AdminDesc{}
+	}
+
+	return descMeta.AdminDesc
+}
+
+func (adminDesc *AdminDesc) DefaultProvenance() *Provenance {
+	if adminDesc.Provenance == nil {
+		adminDesc.Provenance = &Provenance{}
+	}
+
+	return adminDesc.Provenance
+}
+
+func (descMeta *DescMeta) DefaultFindspotDesc() *FindspotDesc {
+	if descMeta.FindspotDesc == nil {
+		descMeta.FindspotDesc = &FindspotDesc{}
+	}
+
+	return descMeta.FindspotDesc
+}
+
+func (findspotDesc *FindspotDesc) DefaultFindspot() *Findspot {
+	if findspotDesc.Findspot == nil {
+		findspotDesc.Findspot = &Findspot{}
+	}
+
+	return findspotDesc.Findspot
+}
+
+func (typeDesc *TypeDesc) DefaultGeographic() *Geographic {
+	if typeDesc.Geographic == nil {
+		typeDesc.Geographic = &Geographic{}
+	}
+
+	return typeDesc.Geographic
+}
+
+func (typeDesc *TypeDesc) DefaultAuthority() *Authority {
+	if typeDesc.Authority == nil {
+		typeDesc.Authority = &Authority{}
+	}
+
+	return typeDesc.Authority
+}
+
+func (typeDesc *TypeDesc) DefaultObverse() *Side {
+	if typeDesc.Obverse == nil {
+		typeDesc.Obverse = &Side{}
+	}
+
+	return typeDesc.Obverse
+}
+
+func (typeDesc *TypeDesc) DefaultReverse() *Side {
+	if typeDesc.Reverse == nil {
+		typeDesc.Reverse = &Side{}
+	}
+
+	return typeDesc.Reverse
+}
+
+func (side *Side) DefaultType() *SideType {
+	if side.Type == nil {
+		side.Type = &SideType{}
+	}
+
+	return side.Type
+}
+
 func (physDesc *PhysDesc) DefaultMeasurementsSet() *MeasurementsSet {
 	if physDesc.MeasurementsSet == nil {
 		physDesc.MeasurementsSet = &MeasurementsSet{}
@@ -549,6 +1214,181 @@ func (typeDesc *TypeDesc) AppendMaterial(material Material) {
 		material)
 }
 
+func (geographic *Geographic) AppendGeogname(geogname Geogname) {
+	if geographic.Geogname == nil {
+		geographic.Geogname = []Geogname{}
+	}
+
+	geographic.Geogname = append(
+		geographic.Geogname,
+		geogname)
+}
+
+func (findspot *Findspot) AppendGeogname(geogname Geogname) {
+	if findspot.Geogname == nil {
+		findspot.Geogname = []Geogname{}
+	}
+
+	findspot.Geogname = append(
+		findspot.Geogname,
+		geogname)
+}
+
+func (findspot *Findspot) AppendDescription(description Description) {
+	if findspot.Description == nil {
+		findspot.Description = []Description{}
+	}
+
+	findspot.Description = append(
+		findspot.Description,
+		description)
+}
+
+func (refDesc *RefDesc) AppendReference(reference Reference) {
+	if refDesc.Reference == nil {
+		refDesc.Reference = []Reference{}
+	}
+
+	refDesc.Reference = append(
+		refDesc.Reference,
+		reference)
+}
+
+func (control *Control) AppendOtherRecordID(otherRecordID OtherRecordID) {
+	if control.OtherRecordID == nil {
+		control.OtherRecordID = []OtherRecordID{}
+	}
+
+	control.OtherRecordID = append(
+		control.OtherRecordID,
+		otherRecordID)
+}
+
+// DeclareSemantic() adds a <semanticDeclaration> for prefix unless it is
+// already declared
+func (control *Control) DeclareSemantic(prefix, namespace string) {
+	for _, declaration := range control.SemanticDeclaration {
+		if declaration.Prefix == prefix {
+			return
+		}
+	}
+
+	control.SemanticDeclaration = append(control.SemanticDeclaration, SemanticDeclaration{
+		Prefix:    prefix,
+		Namespace: namespace,
+	})
+}
+
+func (adminDesc *AdminDesc) AppendAcknowledgment(acknowledgment Acknowlegement) {
+	if adminDesc.Acknowledgment == nil {
+		adminDesc.Acknowledgment = []Acknowlegement{}
+	}
+
+	adminDesc.Acknowledgment = append(
+		adminDesc.Acknowledgment,
+		acknowledgment)
+}
+
+func (adminDesc *AdminDesc) AppendDepartment(department string) {
+	if adminDesc.Department == nil {
+		adminDesc.Department = []string{}
+	}
+
+	adminDesc.Department = append(
+		adminDesc.Department,
+		department)
+}
+
+func (chronList *ChronList) AppendChronItem(chronItem ChronItem) {
+	if chronList.ChronItem == nil {
+		chronList.ChronItem = []ChronItem{}
+	}
+
+	chronList.ChronItem = append(
+		chronList.ChronItem,
+		chronItem)
+}
+
+func (authority *Authority) AppendPersname(persname Persname) {
+	if authority.Persname == nil {
+		authority.Persname = []Persname{}
+	}
+
+	authority.Persname = append(
+		authority.Persname,
+		persname)
+}
+
+func (authority *Authority) AppendCorpname(corpname Corpname) {
+	if authority.Corpname == nil {
+		authority.Corpname = []Corpname{}
+	}
+
+	authority.Corpname = append(
+		authority.Corpname,
+		corpname)
+}
+
+func (authority *Authority) AppendFamname(famname Famname) {
+	if authority.Famname == nil {
+		authority.Famname = []Famname{}
+	}
+
+	authority.Famname = append(
+		authority.Famname,
+		famname)
+}
+
+func (side *Side) AppendLegend(legend Legend) {
+	if side.Legend == nil {
+		side.Legend = []Legend{}
+	}
+
+	side.Legend = append(
+		side.Legend,
+		legend)
+}
+
+func (side *Side) AppendPersname(persname Persname) {
+	if side.Persname == nil {
+		side.Persname = []Persname{}
+	}
+
+	side.Persname = append(
+		side.Persname,
+		persname)
+}
+
+func (side *Side) AppendSymbol(symbol Symbol) {
+	if side.Symbol == nil {
+		side.Symbol = []Symbol{}
+	}
+
+	side.Symbol = append(
+		side.Symbol,
+		symbol)
+}
+
+func (sideType *SideType) AppendDescription(description Description) {
+	if sideType.Description == nil {
+		sideType.Description = []Description{}
+	}
+
+	sideType.Description = append(
+		sideType.Description,
+		description)
+}
+
+func (physDesc *PhysDesc) AppendPeculiarityOfProduction(peculiarity PeculiarityOfProduction) {
+	if physDesc.PeculiarityOfProduction == nil {
+		physDesc.PeculiarityOfProduction = []PeculiarityOfProduction{}
+	}
+
+	physDesc.PeculiarityOfProduction = append(
+		physDesc.PeculiarityOfProduction,
+		peculiarity)
+}
+
 func (fileGrp *FileGrp) AppendFile(file File) {
 	if fileGrp.File == nil {
 		fileGrp.File = []File{}
@@ -559,6 +1399,16 @@ func (fileGrp *FileGrp) AppendFile(file File) {
 		file)
 }
 
+func (maintenanceHistory *MaintenanceHistory) AppendMaintenanceEvent(maintenanceEvent MaintenanceEvent) {
+	if maintenanceHistory.MaintenanceEvent == nil {
+		maintenanceHistory.MaintenanceEvent = []MaintenanceEvent{}
+	}
+
+	maintenanceHistory.MaintenanceEvent = append(
+		maintenanceHistory.MaintenanceEvent,
+		maintenanceEvent)
+}
+
 func (rightsStmt *RightsStmt) AppendLicense(license License) {
 	if rightsStmt.License == nil {
 		rightsStmt.License = []License{}
@@ -616,15 +1466,65 @@ func (maintenanceHistory *MaintenanceHistory) GetOrCreateEventType(eventType str
 
 // Generators
 
+// Namespaces used by NUDS documents
+const (
+	NamespaceNUDS  = "http://nomisma.org/nuds"
+	NamespaceMETS  = "http://www.loc.gov/METS/"
+	NamespaceTEI   = "http://www.tei-c.org/ns/1.0"
+	NamespaceXS    = "http://www.w3.org/2001/XMLSchema"
+	NamespaceXLink = "http://www.w3.org/1999/xlink"
+	NamespaceXSI   = "http://www.w3.org/2001/XMLSchema-instance"
+	NamespaceXML   = "http://www.w3.org/XML/1998/namespace"
+	NamespaceGML   = "http://www.opengis.net/gml"
+	NamespaceMIX   = "http://www.loc.gov/mix/v20"
+
+	SchemaLocation = "http://nomisma.org/nuds http://nomisma.org/nuds.xsd"
+)
+
+// NewImageTechMD() gives the technical metadata of an image with the
+// dimensions in pixels, as MIX
+func NewImageTechMD(width, height int) TechMD {
+	return TechMD{
+		MdWrap: MdWrap{
+			MDTYPE: "NISOIMG",
+			XMLData: XMLData{
+				Mix: &Mix{
+					MIX_NS: NamespaceMIX,
+					BasicImageInformation: BasicImageInformation{
+						BasicImageCharacteristics: BasicImageCharacteristics{
+							ImageWidth:  width,
+							ImageHeight: height,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// NewMaintenanceEvent() records something csv-nuds did to a record
+func NewMaintenanceEvent(eventType string, timestamp time.Time) MaintenanceEvent {
+	return MaintenanceEvent{
+		EventType: EventType{Value: eventType},
+		EventDateTime: EventDateTime{
+			StandardDateTime: timestamp.Format(time.RFC3339),
+			Value:            timestamp.Format("01-02-2006 15:04:05"),
+		},
+		AgentType: AgentType{Value: "machine"},
+		Agent:     Agent{"csv-nuds"},
+	}
+}
+
 func NewNUDS(recordType string, timestamp time.Time) NUDS {
 	return NUDS{
-		XMLNS:          "http://nomisma.org/nuds",
-		METS_NS:        "http://www.loc.gov/METS/",
-		TEI_NS:         "http://www.tei-c.org/ns/1.0",
-		XS_NS:          "http://www.w3.org/2001/XMLSchema",
-		XLINK_NS:       "http://www.w3.org/1999/xlink",
-		XSI_NS:         "http://www.w3.org/2001/XMLSchema-instance",
-		SchemaLocation: "http://nomisma.org/nuds http://nomisma.org/nuds.xsd",
+		XMLNS:          NamespaceNUDS,
+		METS_NS:        NamespaceMETS,
+		TEI_NS:         NamespaceTEI,
+		XS_NS:          NamespaceXS,
+		XLINK_NS:       NamespaceXLink,
+		XSI_NS:         NamespaceXSI,
+		GML_NS:         NamespaceGML,
+		SchemaLocation: SchemaLocation,
 
 		RecordType: recordType,
 
@@ -637,15 +1537,7 @@ func NewNUDS(recordType string, timestamp time.Time) NUDS {
 			},
 			MaintenanceHistory: MaintenanceHistory{
 				MaintenanceEvent: []MaintenanceEvent{
-					{
-						EventType: EventType{Value: "derived"},
-						EventDateTime: EventDateTime{
-							StandardDateTime: timestamp.String(),
-							Value:            timestamp.Format("01-02-2006 15:04:05"),
-						},
-						AgentType: AgentType{Value: "machine"},
-						Agent:     Agent{"csv-nuds"},
-					},
+					NewMaintenanceEvent("derived", timestamp),
 				},
 			},
 		},
@@ -0,0 +1,106 @@
+package simplenuds
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// The prefixes our struct tags use for each namespace.  The structs name
+// elements and attributes with a literal prefix such as "xlink:href",
+// which encoding/xml writes as-is but cannot match when reading, because
+// the decoder resolves prefixes to namespace URIs.
+var canonicalPrefixes = map[string]string{
+	NamespaceNUDS:  "",
+	NamespaceMETS:  "mets",
+	NamespaceTEI:   "tei",
+	NamespaceXS:    "xs",
+	NamespaceXLink: "xlink",
+	NamespaceXSI:   "xsi",
+	NamespaceXML:   "xml",
+	NamespaceGML:   "gml",
+	NamespaceMIX:   "mix",
+}
+
+// Parse() reads a NUDS document, such as one exported from Numishare or
+// numismatics.org.  Elements this package does not model are ignored.
+func Parse(r io.Reader) (*NUDS, error) {
+	decoder := xml.NewTokenDecoder(&prefixingReader{decoder: xml.NewDecoder(r)})
+
+	var nuds NUDS
+	if err := decoder.Decode(&nuds); err != nil {
+		return nil, fmt.Errorf("invalid NUDS: %w", err)
+	}
+
+	if nuds.XMLName.Local != "nuds" {
+		return nil, fmt.Errorf("invalid NUDS: root element is <%s>", nuds.XMLName.Local)
+	}
+
+	// The document may have used other prefixes.  We write ours.
+	nuds.XMLName = xml.Name{}
+	nuds.XMLNS = NamespaceNUDS
+	nuds.METS_NS = NamespaceMETS
+	nuds.TEI_NS = NamespaceTEI
+	nuds.XS_NS = NamespaceXS
+	nuds.XLINK_NS = NamespaceXLink
+	nuds.XSI_NS = NamespaceXSI
+	nuds.GML_NS = NamespaceGML
+
+	if nuds.SchemaLocation == "" {
+		nuds.SchemaLocation = SchemaLocation
+	}
+
+	return &nuds, nil
+}
+
+// prefixingReader is an xml.TokenReader that renames elements and
+// attributes to the canonical prefixed names used in our struct tags,
+// whatever prefix (or default namespace) the document used.
+type prefixingReader struct {
+	decoder *xml.Decoder
+}
+
+func (pr *prefixingReader) Token() (xml.Token, error) {
+	token, err := pr.decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := token.(type) {
+	case xml.StartElement:
+		t = t.Copy()
+		t.Name = canonicalName(t.Name)
+
+		for i, attr := range t.Attr {
+			t.Attr[i].Name = canonicalAttrName(attr.Name)
+		}
+
+		return t, nil
+	case xml.EndElement:
+		t.Name = canonicalName(t.Name)
+		return t, nil
+	}
+
+	return token, nil
+}
+
+func canonicalName(name xml.Name) xml.Name {
+	prefix, ok := canonicalPrefixes[name.Space]
+	if !ok || prefix == "" {
+		return xml.Name{Local: name.Local}
+	}
+
+	return xml.Name{Local: prefix + ":" + name.Local}
+}
+
+func canonicalAttrName(name xml.Name) xml.Name {
+	switch name.Space {
+	case "":
+		return name
+	case "xmlns":
+		// Namespace declarations, e.g. xmlns:xlink
+		return xml.Name{Local: "xmlns:" + name.Local}
+	}
+
+	return canonicalName(name)
+}
@@ -0,0 +1,156 @@
+package simplenuds
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// Reading and re-writing the converter's golden file must reproduce it
+func TestParseGolden(t *testing.T) {
+	want, err := os.ReadFile("../converter/testdata/nuds264199.xml.golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nuds, err := Parse(bytes.NewReader(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := xml.MarshalIndent(nuds, " ", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != string(want) {
+		t.Errorf("Want:\n%s\nGot:\n%s", want, got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		file  string
+		check func(t *testing.T, nuds *NUDS)
+	}{
+		{
+			file: "testdata/1922.999.73.xml",
+			check: func(t *testing.T, nuds *NUDS) {
+				if nuds.Control.RecordID != "1922.999.73" || nuds.RecordType != "physical" {
+					t.Errorf("control %+v", nuds.Control)
+				}
+
+				if len(nuds.Control.RightsStmt.License) != 2 ||
+					nuds.Control.RightsStmt.License[1].Href != "https://creativecommons.org/choose/mark/" {
+					t.Errorf("licenses %+v", nuds.Control.RightsStmt.License)
+				}
+
+				typeDesc := nuds.DescMeta.TypeDesc
+				if typeDesc.DateRange == nil || typeDesc.DateRange.ToDate.StandardDate != "0628" {
+					t.Errorf("dateRange %+v", typeDesc.DateRange)
+				}
+
+				if typeDesc.Material[0].HRef != "http://nomisma.org/id/ar" {
+					t.Errorf("material %+v", typeDesc.Material)
+				}
+
+				if typeDesc.Geographic.Geogname[0] != (Geogname{
+					Role: "mint", Type: "simple", Href: "http://nomisma.org/id/merv", Value: "Merv",
+				}) {
+					t.Errorf("geogname %+v", typeDesc.Geographic.Geogname)
+				}
+
+				if nuds.DescMeta.PhysDesc.MeasurementsSet.Weight.Value != "4.1" {
+					t.Errorf("weight %+v", nuds.DescMeta.PhysDesc.MeasurementsSet.Weight)
+				}
+
+				fileGrp := nuds.DigRep.FileSec.FileGrp[0]
+				if fileGrp.USE != "obverse" || fileGrp.File[0].FLocat[0].LOCTYPE != "URL" {
+					t.Errorf("fileGrp %+v", fileGrp)
+				}
+			},
+		},
+		{
+			file: "testdata/prefixes.xml",
+			check: func(t *testing.T, nuds *NUDS) {
+				if nuds.DescMeta.Title[0] != (Title{Lang: "de", Value: "Drachme"}) {
+					t.Errorf("title %+v", nuds.DescMeta.Title)
+				}
+
+				if nuds.DescMeta.NoteSet[0].Note[0].Value != "Date: x2" {
+					t.Errorf("noteSet %+v", nuds.DescMeta.NoteSet)
+				}
+
+				date := nuds.DescMeta.TypeDesc.Date
+				if date == nil || date.StandardDate != "-0100" || date.Certainty != "circa" {
+					t.Errorf("date %+v", date)
+				}
+
+				if nuds.DescMeta.TypeDesc.Material[0].HRef != "http://nomisma.org/id/ar" {
+					t.Errorf("material %+v", nuds.DescMeta.TypeDesc.Material)
+				}
+
+				obverse := nuds.DescMeta.TypeDesc.Obverse
+				if obverse == nil || obverse.Type.Description[0] != (Description{Lang: "de", Value: "Kopf des Herakles"}) {
+					t.Errorf("obverse %+v", obverse)
+				} else if legend := obverse.Legend[0]; legend.ScriptPhysical != "Greek" || legend.TEI == nil ||
+					legend.TEI.Type != "edition" || len(legend.TEI.Ab) != 2 || legend.TEI.Ab[1].Value != "ΑΛΕΞΑΝΔΡΟΥ" {
+					t.Errorf("legend %+v", obverse.Legend)
+				}
+
+				if findspot := nuds.DescMeta.FindspotDesc.Findspot; findspot.Geogname[0].Value != "Nineveh" ||
+					findspot.Location == nil || findspot.Location.Point.Pos != "36.36 43.15" {
+					t.Errorf("findspot %+v", findspot)
+				}
+
+				if href := nuds.DigRep.FileSec.FileGrp[0].File[0].FLocat[0].Href; href != "https://example.org/215654.jpg" {
+					t.Errorf("FLocat href %q", href)
+				}
+
+				if nuds.XLINK_NS != NamespaceXLink {
+					t.Errorf("xlink namespace %q", nuds.XLINK_NS)
+				}
+			},
+		},
+	}
+
+	for _, testcase := range tests {
+		t.Run(testcase.file, func(t *testing.T) {
+			f, err := os.Open(testcase.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			nuds, err := Parse(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			testcase.check(t, nuds)
+
+			// What we write, we must read back unchanged
+			data, err := xml.Marshal(nuds)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			again, err := Parse(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(nuds, again) {
+				t.Errorf("round trip changed record:\n%+v\n%+v", nuds, again)
+			}
+		})
+	}
+}
+
+func TestParseNotNUDS(t *testing.T) {
+	if _, err := Parse(bytes.NewReader([]byte(`<mets xmlns="http://www.loc.gov/METS/"/>`))); err == nil {
+		t.Error("expected error for non-NUDS document")
+	}
+}
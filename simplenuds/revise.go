@@ -0,0 +1,75 @@
+package simplenuds
+
+import (
+	"bytes"
+	"encoding/xml"
+	"time"
+)
+
+// Maintenance events that csv-nuds writes each time it converts a record
+var conversionEvents = map[string]bool{
+	"derived": true,
+	"revised": true,
+}
+
+// SameContent() is true if two records differ only in what conversion
+// writes each time: the maintenance status and the "derived" and "revised"
+// maintenance events.
+func SameContent(a, b *NUDS) bool {
+	dataA, errA := xml.Marshal(withoutConversion(a))
+	dataB, errB := xml.Marshal(withoutConversion(b))
+
+	return errA == nil && errB == nil && bytes.Equal(dataA, dataB)
+}
+
+func withoutConversion(nuds *NUDS) *NUDS {
+	retval := *nuds
+	retval.Control.MaintenanceStatus = MaintenanceStatus{}
+	retval.Control.MaintenanceHistory.MaintenanceEvent = nil
+
+	for _, event := range nuds.Control.MaintenanceHistory.MaintenanceEvent {
+		if !conversionEvents[event.EventType.Value] {
+			retval.Control.MaintenanceHistory.AppendMaintenanceEvent(event)
+		}
+	}
+
+	return &retval
+}
+
+// Revise() makes a newly converted record a revision of previous, the
+// same record as converted before.  The record keeps the history of
+// previous, with its original "derived" event, and gains a "revised" event.
+func (nuds *NUDS) Revise(previous *NUDS, timestamp time.Time) {
+	history := &nuds.Control.MaintenanceHistory
+	events := history.MaintenanceEvent
+	history.MaintenanceEvent = nil
+
+	var previousDerived *MaintenanceEvent
+
+	var revisions []MaintenanceEvent
+
+	for i, event := range previous.Control.MaintenanceHistory.MaintenanceEvent {
+		switch event.EventType.Value {
+		case "derived":
+			previousDerived = &previous.Control.MaintenanceHistory.MaintenanceEvent[i]
+		case "revised":
+			revisions = append(revisions, event)
+		}
+	}
+
+	for _, event := range events {
+		if event.EventType.Value == "derived" && previousDerived != nil {
+			event = *previousDerived
+		}
+
+		history.AppendMaintenanceEvent(event)
+	}
+
+	for _, event := range revisions {
+		history.AppendMaintenanceEvent(event)
+	}
+
+	history.AppendMaintenanceEvent(NewMaintenanceEvent("revised", timestamp))
+
+	nuds.Control.MaintenanceStatus.Value = "revised"
+}
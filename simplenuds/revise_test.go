@@ -0,0 +1,77 @@
+package simplenuds
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRevise(t *testing.T) {
+	first := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	second := time.Date(2021, 4, 1, 12, 0, 0, 0, time.UTC)
+	third := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	newRecord := func(timestamp time.Time, weight string) *NUDS {
+		nuds := NewNUDS("physical", timestamp)
+		nuds.Control.RecordID = "58627"
+		created := nuds.Control.MaintenanceHistory.GetOrCreateEventType("created")
+		created.AgentType.Value = "human"
+		created.Agent.Value = "viur"
+		nuds.DescMeta.DefaultTitle()[0] = Title{Lang: "en", Value: "AY, Sasanian AR drachm, Khusru II"}
+		nuds.DescMeta.DefaultPhysDesc().DefaultMeasurementsSet().Weight = &Weight{Units: "g", Value: weight}
+
+		return &nuds
+	}
+
+	previous := newRecord(first, "3.7")
+
+	if !SameContent(newRecord(second, "3.7"), previous) {
+		t.Errorf("records converted at different times should have the same content")
+	}
+
+	revised := newRecord(second, "3.8")
+	if SameContent(revised, previous) {
+		t.Fatalf("records with different weights should differ")
+	}
+
+	revised.Revise(previous, second)
+
+	// A record changed twice keeps both revisions
+	again := newRecord(third, "3.9")
+	again.Revise(revised, third)
+
+	if again.Control.MaintenanceStatus.Value != "revised" {
+		t.Errorf("got maintenanceStatus %q", again.Control.MaintenanceStatus.Value)
+	}
+
+	var got []string
+	for _, event := range again.Control.MaintenanceHistory.MaintenanceEvent {
+		got = append(got, event.EventType.Value+" "+event.EventDateTime.StandardDateTime)
+	}
+
+	expected := []string{
+		"derived 2021-03-01T12:00:00Z",
+		"created ",
+		"revised 2021-04-01T12:00:00Z",
+		"revised 2021-05-01T12:00:00Z",
+	}
+
+	if len(got) != len(expected) {
+		t.Fatalf("got events %q, expected %q", got, expected)
+	}
+
+	for i := range got {
+		if got[i] != expected[i] {
+			t.Errorf("got events %q, expected %q", got, expected)
+			break
+		}
+	}
+
+	// Revisions are not changes to the content
+	if !SameContent(again, newRecord(third, "3.9")) {
+		t.Errorf("a revised record should have the same content as a new conversion")
+	}
+
+	if report, err := CheckSchema(again); err != nil || !report.OK() {
+		t.Errorf("%v %+v", err, report)
+	}
+}
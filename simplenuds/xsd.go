@@ -0,0 +1,810 @@
+package simplenuds
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A small W3C XML Schema processor, enough for the schemas in schema/.
+// It supports global and local element, attribute, attributeGroup,
+// complexType and simpleType definitions; sequence, choice and any
+// particles with minOccurs/maxOccurs; simpleContent extension; and
+// simpleType restriction (enumeration, pattern, length and numeric range
+// facets) and union.
+
+const namespaceXSD = "http://www.w3.org/2001/XMLSchema"
+
+const unbounded = -1
+
+// xsdNode is an element of a schema document, with the in-scope
+// namespace prefixes needed to resolve QName-valued attributes.
+type xsdNode struct {
+	Name     string
+	Attr     map[string]string
+	Children []*xsdNode
+
+	// Prefix to namespace
+	NS map[string]string
+
+	// Target namespace of the schema document
+	TargetNS string
+}
+
+// The QName in attribute attr, e.g. type="xs:string", resolved to a namespace
+func (node *xsdNode) qname(attr string) (xml.Name, bool) {
+	val, ok := node.Attr[attr]
+	if !ok {
+		return xml.Name{}, false
+	}
+
+	prefix, local := "", val
+	if i := strings.IndexByte(val, ':'); i >= 0 {
+		prefix, local = val[:i], val[i+1:]
+	}
+
+	if prefix == "xml" {
+		return xml.Name{Space: NamespaceXML, Local: local}, true
+	}
+
+	return xml.Name{Space: node.NS[prefix], Local: local}, true
+}
+
+func (node *xsdNode) occurs() (int, int) {
+	min, max := 1, 1
+
+	if val, ok := node.Attr["minOccurs"]; ok {
+		min, _ = strconv.Atoi(val)
+	}
+
+	if val, ok := node.Attr["maxOccurs"]; ok {
+		if val == "unbounded" {
+			max = unbounded
+		} else {
+			max, _ = strconv.Atoi(val)
+		}
+	}
+
+	return min, max
+}
+
+type elementDecl struct {
+	Name     xml.Name
+	TypeName xml.Name
+	Type     *typeDef
+}
+
+type attrDecl struct {
+	Name     xml.Name
+	TypeName xml.Name
+	Type     *typeDef
+}
+
+type attrUse struct {
+	Decl     *attrDecl
+	Required bool
+}
+
+type particleKind int
+
+const (
+	particleElement particleKind = iota
+	particleSequence
+	particleChoice
+	particleAny
+)
+
+type particle struct {
+	Kind     particleKind
+	Min, Max int
+
+	// For particleElement
+	Element *elementDecl
+
+	// For particleSequence and particleChoice
+	Children []*particle
+}
+
+type typeDef struct {
+	Simple bool
+
+	// Simple types
+	Base        xml.Name
+	BaseType    *typeDef
+	Union       []*typeDef
+	Enumeration []string
+	Patterns    []*regexp.Regexp
+	MinLength   int
+	MaxLength   int
+	MinValue    *bound
+	MaxValue    *bound
+
+	// Complex types
+	Mixed         bool
+	Content       *particle
+	SimpleContent *typeDef
+	Attrs         []attrUse
+	AnyAttribute  bool
+}
+
+type bound struct {
+	Value     float64
+	Inclusive bool
+}
+
+type xsdSchema struct {
+	elements   map[xml.Name]*elementDecl
+	attributes map[xml.Name]*attrDecl
+	types      map[xml.Name]*typeDef
+	attrGroups map[xml.Name]*xsdNode
+
+	// Definitions not yet compiled
+	pendingElements map[xml.Name]*xsdNode
+	pendingAttrs    map[xml.Name]*xsdNode
+	pendingTypes    map[xml.Name]*xsdNode
+}
+
+// loadSchema() reads the schema document name, and any documents it
+// imports, from fsys.
+func loadSchema(fsys fs.FS, name string) (*xsdSchema, error) {
+	schema := &xsdSchema{
+		elements:        map[xml.Name]*elementDecl{},
+		attributes:      map[xml.Name]*attrDecl{},
+		types:           map[xml.Name]*typeDef{},
+		attrGroups:      map[xml.Name]*xsdNode{},
+		pendingElements: map[xml.Name]*xsdNode{},
+		pendingAttrs:    map[xml.Name]*xsdNode{},
+		pendingTypes:    map[xml.Name]*xsdNode{},
+	}
+
+	if err := schema.load(fsys, name, map[string]bool{}); err != nil {
+		return nil, err
+	}
+
+	for name, node := range schema.pendingElements {
+		if _, err := schema.element(name, node); err != nil {
+			return nil, err
+		}
+	}
+
+	for name := range schema.pendingTypes {
+		if _, err := schema.typeNamed(name); err != nil {
+			return nil, err
+		}
+	}
+
+	return schema, nil
+}
+
+func (schema *xsdSchema) load(fsys fs.FS, name string, loaded map[string]bool) error {
+	if loaded[name] {
+		return nil
+	}
+
+	loaded[name] = true
+
+	f, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	root, err := readXSDNode(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	for _, child := range root.Children {
+		childName := xml.Name{Space: root.TargetNS, Local: child.Attr["name"]}
+
+		switch child.Name {
+		case "import", "include":
+			if location, ok := child.Attr["schemaLocation"]; ok {
+				err := schema.load(fsys, path.Join(path.Dir(name), location), loaded)
+				if err != nil {
+					return err
+				}
+			}
+		case "element":
+			schema.pendingElements[childName] = child
+		case "attribute":
+			schema.pendingAttrs[childName] = child
+		case "complexType", "simpleType":
+			schema.pendingTypes[childName] = child
+		case "attributeGroup":
+			schema.attrGroups[childName] = child
+		case "annotation":
+		default:
+			return fmt.Errorf("%s: unsupported <xs:%s>", name, child.Name)
+		}
+	}
+
+	return nil
+}
+
+// readXSDNode() parses a schema document into a tree of xsdNodes
+func readXSDNode(r io.Reader) (*xsdNode, error) {
+	decoder := xml.NewDecoder(r)
+
+	var stack []*xsdNode
+
+	var root *xsdNode
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xsdNode{
+				Name: t.Name.Local,
+				Attr: map[string]string{},
+				NS:   map[string]string{},
+			}
+
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				for prefix, ns := range parent.NS {
+					node.NS[prefix] = ns
+				}
+
+				node.TargetNS = parent.TargetNS
+				parent.Children = append(parent.Children, node)
+			}
+
+			for _, attr := range t.Attr {
+				switch {
+				case attr.Name.Space == "xmlns":
+					node.NS[attr.Name.Local] = attr.Value
+				case attr.Name.Space == "" && attr.Name.Local == "xmlns":
+					node.NS[""] = attr.Value
+				case attr.Name.Space == "":
+					node.Attr[attr.Name.Local] = attr.Value
+				}
+			}
+
+			if t.Name.Space != namespaceXSD {
+				return nil, fmt.Errorf("unexpected element %s in schema", t.Name.Local)
+			}
+
+			if len(stack) == 0 {
+				if t.Name.Local != "schema" {
+					return nil, fmt.Errorf("root element is <%s>, not <schema>", t.Name.Local)
+				}
+
+				node.TargetNS = node.Attr["targetNamespace"]
+				root = node
+			}
+
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("empty schema")
+	}
+
+	return root, nil
+}
+
+// element() compiles a global element declaration
+func (schema *xsdSchema) element(name xml.Name, node *xsdNode) (*elementDecl, error) {
+	if decl, ok := schema.elements[name]; ok {
+		return decl, nil
+	}
+
+	decl := &elementDecl{Name: name}
+
+	// Register first, so recursive content models terminate
+	schema.elements[name] = decl
+
+	return decl, schema.compileElement(decl, node)
+}
+
+func (schema *xsdSchema) elementRef(name xml.Name) (*elementDecl, error) {
+	node, ok := schema.pendingElements[name]
+	if !ok {
+		return nil, fmt.Errorf("undefined element %s", name.Local)
+	}
+
+	return schema.element(name, node)
+}
+
+func (schema *xsdSchema) compileElement(decl *elementDecl, node *xsdNode) error {
+	var err error
+
+	if typeName, ok := node.qname("type"); ok {
+		decl.TypeName = typeName
+		decl.Type, err = schema.typeNamed(typeName)
+
+		return err
+	}
+
+	for _, child := range node.Children {
+		switch child.Name {
+		case "complexType":
+			decl.Type, err = schema.complexType(child)
+		case "simpleType":
+			decl.Type, err = schema.simpleType(child)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if decl.Type == nil {
+		// An element with no type may contain anything
+		decl.Type = &typeDef{Mixed: true, Content: &particle{Kind: particleAny, Min: 0, Max: unbounded}, AnyAttribute: true}
+	}
+
+	return nil
+}
+
+// typeNamed() returns a built-in or global type
+func (schema *xsdSchema) typeNamed(name xml.Name) (*typeDef, error) {
+	if name.Space == namespaceXSD {
+		if _, ok := builtinTypes[name.Local]; !ok {
+			return nil, fmt.Errorf("unsupported built-in type xs:%s", name.Local)
+		}
+
+		return &typeDef{Simple: true, Base: name, MaxLength: -1}, nil
+	}
+
+	if def, ok := schema.types[name]; ok {
+		return def, nil
+	}
+
+	node, ok := schema.pendingTypes[name]
+	if !ok {
+		return nil, fmt.Errorf("undefined type %s", name.Local)
+	}
+
+	var def *typeDef
+
+	var err error
+
+	if node.Name == "simpleType" {
+		def, err = schema.simpleType(node)
+	} else {
+		def, err = schema.complexType(node)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	schema.types[name] = def
+
+	return def, nil
+}
+
+func (schema *xsdSchema) simpleType(node *xsdNode) (*typeDef, error) {
+	def := &typeDef{Simple: true, MaxLength: -1}
+
+	for _, child := range node.Children {
+		switch child.Name {
+		case "restriction":
+			if err := schema.restriction(def, child); err != nil {
+				return nil, err
+			}
+		case "union":
+			for _, member := range strings.Fields(child.Attr["memberTypes"]) {
+				memberNode := &xsdNode{Attr: map[string]string{"type": member}, NS: child.NS}
+				memberName, _ := memberNode.qname("type")
+
+				memberType, err := schema.typeNamed(memberName)
+				if err != nil {
+					return nil, err
+				}
+
+				def.Union = append(def.Union, memberType)
+			}
+		case "annotation":
+		default:
+			return nil, fmt.Errorf("unsupported <xs:%s> in simpleType", child.Name)
+		}
+	}
+
+	return def, nil
+}
+
+func (schema *xsdSchema) restriction(def *typeDef, node *xsdNode) error {
+	if base, ok := node.qname("base"); ok {
+		baseType, err := schema.typeNamed(base)
+		if err != nil {
+			return err
+		}
+
+		def.BaseType = baseType
+	}
+
+	for _, facet := range node.Children {
+		val := facet.Attr["value"]
+
+		switch facet.Name {
+		case "enumeration":
+			def.Enumeration = append(def.Enumeration, val)
+		case "pattern":
+			re, err := regexp.Compile("^(?:" + val + ")$")
+			if err != nil {
+				return fmt.Errorf("unsupported pattern %q: %w", val, err)
+			}
+
+			def.Patterns = append(def.Patterns, re)
+		case "minLength", "maxLength", "length":
+			n, err := strconv.Atoi(val)
+			if err != nil {
+				return fmt.Errorf("invalid %s %q", facet.Name, val)
+			}
+
+			if facet.Name != "maxLength" {
+				def.MinLength = n
+			}
+
+			if facet.Name != "minLength" {
+				def.MaxLength = n
+			}
+		case "minInclusive", "minExclusive", "maxInclusive", "maxExclusive":
+			n, err := strconv.ParseFloat(val, 64)
+			if err != nil {
+				return fmt.Errorf("invalid %s %q", facet.Name, val)
+			}
+
+			b := &bound{Value: n, Inclusive: strings.HasSuffix(facet.Name, "Inclusive")}
+			if strings.HasPrefix(facet.Name, "min") {
+				def.MinValue = b
+			} else {
+				def.MaxValue = b
+			}
+		case "annotation":
+		default:
+			return fmt.Errorf("unsupported facet <xs:%s>", facet.Name)
+		}
+	}
+
+	return nil
+}
+
+func (schema *xsdSchema) complexType(node *xsdNode) (*typeDef, error) {
+	def := &typeDef{Mixed: node.Attr["mixed"] == "true"}
+
+	for _, child := range node.Children {
+		var err error
+
+		switch child.Name {
+		case "sequence", "choice":
+			def.Content, err = schema.particle(child)
+		case "simpleContent":
+			err = schema.simpleContent(def, child)
+		case "attribute", "attributeGroup", "anyAttribute":
+			err = schema.attribute(def, child)
+		case "annotation":
+		default:
+			err = fmt.Errorf("unsupported <xs:%s> in complexType", child.Name)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return def, nil
+}
+
+func (schema *xsdSchema) simpleContent(def *typeDef, node *xsdNode) error {
+	for _, child := range node.Children {
+		if child.Name != "extension" {
+			return fmt.Errorf("unsupported <xs:%s> in simpleContent", child.Name)
+		}
+
+		base, _ := child.qname("base")
+
+		baseType, err := schema.typeNamed(base)
+		if err != nil {
+			return err
+		}
+
+		if !baseType.Simple {
+			// Extending a complex type with simple content
+			def.SimpleContent = baseType.SimpleContent
+			def.Attrs = append(def.Attrs, baseType.Attrs...)
+		} else {
+			def.SimpleContent = baseType
+		}
+
+		for _, attr := range child.Children {
+			if err := schema.attribute(def, attr); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// attribute() adds an attribute, attribute group or wildcard to a complex type
+func (schema *xsdSchema) attribute(def *typeDef, node *xsdNode) error {
+	switch node.Name {
+	case "anyAttribute":
+		def.AnyAttribute = true
+		return nil
+	case "attributeGroup":
+		ref, _ := node.qname("ref")
+
+		group, ok := schema.attrGroups[ref]
+		if !ok {
+			return fmt.Errorf("undefined attributeGroup %s", ref.Local)
+		}
+
+		for _, child := range group.Children {
+			if err := schema.attribute(def, child); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case "attribute":
+	case "annotation":
+		return nil
+	default:
+		return fmt.Errorf("unsupported <xs:%s> in attributes", node.Name)
+	}
+
+	use := attrUse{Required: node.Attr["use"] == "required"}
+
+	if node.Attr["use"] == "prohibited" {
+		return nil
+	}
+
+	if ref, ok := node.qname("ref"); ok {
+		decl, err := schema.globalAttribute(ref)
+		if err != nil {
+			return err
+		}
+
+		use.Decl = decl
+	} else {
+		// Local attributes are unqualified
+		decl, err := schema.compileAttribute(xml.Name{Local: node.Attr["name"]}, node)
+		if err != nil {
+			return err
+		}
+
+		use.Decl = decl
+	}
+
+	def.Attrs = append(def.Attrs, use)
+
+	return nil
+}
+
+func (schema *xsdSchema) globalAttribute(name xml.Name) (*attrDecl, error) {
+	if decl, ok := schema.attributes[name]; ok {
+		return decl, nil
+	}
+
+	node, ok := schema.pendingAttrs[name]
+	if !ok {
+		return nil, fmt.Errorf("undefined attribute %s", name.Local)
+	}
+
+	decl, err := schema.compileAttribute(name, node)
+	if err != nil {
+		return nil, err
+	}
+
+	schema.attributes[name] = decl
+
+	return decl, nil
+}
+
+func (schema *xsdSchema) compileAttribute(name xml.Name, node *xsdNode) (*attrDecl, error) {
+	decl := &attrDecl{Name: name}
+
+	var err error
+
+	if typeName, ok := node.qname("type"); ok {
+		decl.TypeName = typeName
+		decl.Type, err = schema.typeNamed(typeName)
+
+		return decl, err
+	}
+
+	for _, child := range node.Children {
+		if child.Name == "simpleType" {
+			decl.Type, err = schema.simpleType(child)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if decl.Type == nil {
+		decl.Type = &typeDef{Simple: true, Base: xml.Name{Space: namespaceXSD, Local: "anySimpleType"}, MaxLength: -1}
+	}
+
+	return decl, nil
+}
+
+func (schema *xsdSchema) particle(node *xsdNode) (*particle, error) {
+	p := &particle{}
+	p.Min, p.Max = node.occurs()
+
+	switch node.Name {
+	case "element":
+		p.Kind = particleElement
+
+		if ref, ok := node.qname("ref"); ok {
+			decl, err := schema.elementRef(ref)
+			if err != nil {
+				return nil, err
+			}
+
+			p.Element = decl
+		} else {
+			p.Element = &elementDecl{Name: xml.Name{Space: node.TargetNS, Local: node.Attr["name"]}}
+			if err := schema.compileElement(p.Element, node); err != nil {
+				return nil, err
+			}
+		}
+	case "any":
+		p.Kind = particleAny
+	case "sequence", "choice":
+		p.Kind = particleSequence
+		if node.Name == "choice" {
+			p.Kind = particleChoice
+		}
+
+		for _, child := range node.Children {
+			if child.Name == "annotation" {
+				continue
+			}
+
+			childParticle, err := schema.particle(child)
+			if err != nil {
+				return nil, err
+			}
+
+			p.Children = append(p.Children, childParticle)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported <xs:%s> in content model", node.Name)
+	}
+
+	return p, nil
+}
+
+// Built-in simple types, with a pattern their lexical form must match
+var builtinTypes = map[string]*regexp.Regexp{
+	"anySimpleType":      nil,
+	"string":             nil,
+	"normalizedString":   nil,
+	"token":              nil,
+	"anyURI":             nil,
+	"language":           regexp.MustCompile(`^[a-zA-Z]{1,8}(-[a-zA-Z0-9]{1,8})*$`),
+	"NCName":             regexp.MustCompile(`^[\pL_][\pL\pN._-]*$`),
+	"ID":                 regexp.MustCompile(`^[\pL_][\pL\pN._-]*$`),
+	"IDREFS":             regexp.MustCompile(`^[\pL_][\pL\pN._-]*(\s+[\pL_][\pL\pN._-]*)*$`),
+	"boolean":            regexp.MustCompile(`^(true|false|1|0)$`),
+	"decimal":            regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`),
+	"double":             regexp.MustCompile(`^([+-]?(\d+(\.\d*)?|\.\d+)([eE][+-]?\d+)?|INF|-INF|NaN)$`),
+	"integer":            regexp.MustCompile(`^[+-]?\d+$`),
+	"long":               regexp.MustCompile(`^[+-]?\d+$`),
+	"int":                regexp.MustCompile(`^[+-]?\d+$`),
+	"nonNegativeInteger": regexp.MustCompile(`^\+?\d+$`),
+	"positiveInteger":    regexp.MustCompile(`^\+?0*[1-9]\d*$`),
+	"dateTime":           regexp.MustCompile(`^-?\d{4,}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01])T([01]\d|2[0-3]):[0-5]\d:[0-5]\d(\.\d+)?(Z|[+-]\d{2}:\d{2})?$`),
+	"date":               regexp.MustCompile(`^-?\d{4,}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01])(Z|[+-]\d{2}:\d{2})?$`),
+	"gYearMonth":         regexp.MustCompile(`^-?\d{4,}-(0[1-9]|1[0-2])(Z|[+-]\d{2}:\d{2})?$`),
+	"gYear":              regexp.MustCompile(`^-?\d{4,}(Z|[+-]\d{2}:\d{2})?$`),
+}
+
+// check() returns why val is not valid for the simple type, or ""
+func (def *typeDef) check(val string) string {
+	if len(def.Union) > 0 {
+		for _, member := range def.Union {
+			if member.check(val) == "" {
+				return ""
+			}
+		}
+
+		return fmt.Sprintf("%q is not valid for any member of the union", val)
+	}
+
+	if def.BaseType != nil {
+		if msg := def.BaseType.check(val); msg != "" {
+			return msg
+		}
+	}
+
+	if def.Base.Local != "" {
+		if msg := checkBuiltin(def.Base.Local, val); msg != "" {
+			return msg
+		}
+	}
+
+	if len(def.Enumeration) > 0 && !contains(def.Enumeration, val) {
+		return fmt.Sprintf("%q is not one of %s", val, strings.Join(def.Enumeration, ", "))
+	}
+
+	for _, re := range def.Patterns {
+		if !re.MatchString(val) {
+			return fmt.Sprintf("%q does not match pattern %s", val, re)
+		}
+	}
+
+	length := len([]rune(val))
+	if length < def.MinLength {
+		return fmt.Sprintf("%q is shorter than %d", val, def.MinLength)
+	}
+
+	if def.MaxLength >= 0 && length > def.MaxLength {
+		return fmt.Sprintf("%q is longer than %d", val, def.MaxLength)
+	}
+
+	if def.MinValue != nil || def.MaxValue != nil {
+		n, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		if err != nil || math.IsNaN(n) {
+			return fmt.Sprintf("%q is not a number", val)
+		}
+
+		if def.MinValue != nil && (n < def.MinValue.Value || (!def.MinValue.Inclusive && n == def.MinValue.Value)) {
+			return fmt.Sprintf("%q is below the minimum %v", val, def.MinValue.Value)
+		}
+
+		if def.MaxValue != nil && (n > def.MaxValue.Value || (!def.MaxValue.Inclusive && n == def.MaxValue.Value)) {
+			return fmt.Sprintf("%q is above the maximum %v", val, def.MaxValue.Value)
+		}
+	}
+
+	return ""
+}
+
+func checkBuiltin(name string, val string) string {
+	switch name {
+	case "anySimpleType", "string", "normalizedString", "token":
+		return ""
+	case "anyURI":
+		if strings.ContainsAny(val, " \t\n") {
+			return fmt.Sprintf("%q is not a valid xs:anyURI", val)
+		}
+
+		if _, err := url.Parse(val); err != nil {
+			return fmt.Sprintf("%q is not a valid xs:anyURI", val)
+		}
+
+		return ""
+	}
+
+	if re := builtinTypes[name]; re != nil && !re.MatchString(strings.TrimSpace(val)) {
+		return fmt.Sprintf("%q is not a valid xs:%s", val, name)
+	}
+
+	return ""
+}
+
+func contains(vals []string, val string) bool {
+	for _, v := range vals {
+		if v == val {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,323 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// OpenDocument namespaces
+const (
+	nsOffice = "urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+	nsTable  = "urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+	nsText   = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+)
+
+// ReadODS() reads a sheet of an OpenDocument spreadsheet.  Cells give
+// the text as displayed, so formatted numbers keep their leading zeros.
+func ReadODS(r io.ReaderAt, size int64, sheet string) (*Reader, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	// A sheet's name is only known on reaching it, so skip through the
+	// sheets for their names, then read the chosen one from the start
+	names, err := odsSheetNames(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	i, err := chooseSheet(names, sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := openZipFile(archive, "content.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	table := &odsTable{decoder: xml.NewDecoder(f), line: 1}
+
+	if err = table.seek(i); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("content.xml: %w", err)
+	}
+
+	nextRow := func() ([]string, int, error) {
+		cells, line, err := table.next()
+		if err != nil && err != io.EOF {
+			err = fmt.Errorf("content.xml: sheet %q: %w", names[i], err)
+		}
+
+		return cells, line, err
+	}
+
+	return &Reader{nextRow: nextRow, closers: []io.Closer{f}}, nil
+}
+
+// odsSheetNames() returns the name of each <table:table> of content.xml
+func odsSheetNames(archive *zip.Reader) ([]string, error) {
+	f, err := openZipFile(archive, "content.xml")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var names []string
+
+	decoder := xml.NewDecoder(f)
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return names, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("content.xml: %w", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Space != nsTable || start.Name.Local != "table" {
+			continue
+		}
+
+		names = append(names, odsAttr(start, nsTable, "name"))
+
+		if err = decoder.Skip(); err != nil {
+			return nil, fmt.Errorf("content.xml: sheet %q: %w", names[len(names)-1], err)
+		}
+	}
+}
+
+// odsTable reads the rows of a <table:table> one at a time
+type odsTable struct {
+	decoder *xml.Decoder
+
+	// Row number of the next row
+	line int
+
+	// A repeated row, still to be returned repeat more times
+	row    []string
+	repeat int
+}
+
+// seek() reads up to the start of the i'th <table:table>, from 0
+func (table *odsTable) seek(i int) error {
+	for {
+		token, err := table.decoder.Token()
+		if err == io.EOF {
+			return fmt.Errorf("sheet %d missing", i+1)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Space != nsTable || start.Name.Local != "table" {
+			continue
+		}
+
+		if i == 0 {
+			return nil
+		}
+
+		if err = table.decoder.Skip(); err != nil {
+			return err
+		}
+
+		i--
+	}
+}
+
+// next() returns the next row with a value and its row number, or
+// io.EOF at the end of the table
+func (table *odsTable) next() ([]string, int, error) {
+	for table.repeat == 0 {
+		cells, repeat, err := table.readRow()
+		if err != nil {
+			return nil, 0, err
+		}
+
+		// Repeated rows are usually the empty rest of the sheet
+		if len(cells) == 0 {
+			table.line += repeat
+			continue
+		}
+
+		table.row, table.repeat = cells, repeat
+	}
+
+	table.repeat--
+	table.line++
+
+	return append([]string(nil), table.row...), table.line - 1, nil
+}
+
+// readRow() reads the next <table:table-row>, returning its cells and
+// how many times it is repeated, or io.EOF at the end of the table
+func (table *odsTable) readRow() ([]string, int, error) {
+	decoder := table.decoder
+
+	var cells []string
+
+	// Empty cells not yet known to be followed by a value
+	emptyCells := 0
+
+	rowRepeat := 1
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, 0, io.ErrUnexpectedEOF
+		}
+
+		if err != nil {
+			return nil, 0, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Space != nsTable {
+				if err = decoder.Skip(); err != nil {
+					return nil, 0, err
+				}
+
+				continue
+			}
+
+			switch t.Name.Local {
+			case "table-row":
+				cells = nil
+				emptyCells = 0
+				rowRepeat = odsRepeat(t, "number-rows-repeated")
+			case "table-cell", "covered-table-cell":
+				val, err := readODSCell(decoder, t)
+				if err != nil {
+					return nil, 0, err
+				}
+
+				repeat := odsRepeat(t, "number-columns-repeated")
+				if val == "" {
+					emptyCells += repeat
+					continue
+				}
+
+				for ; emptyCells > 0; emptyCells-- {
+					cells = append(cells, "")
+				}
+
+				for i := 0; i < repeat; i++ {
+					cells = append(cells, val)
+				}
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "table-row":
+				return cells, rowRepeat, nil
+			case "table":
+				return nil, 0, io.EOF
+			}
+		}
+	}
+}
+
+// readODSCell() returns the text of a cell, one line per paragraph
+func readODSCell(decoder *xml.Decoder, start xml.StartElement) (string, error) {
+	var sb strings.Builder
+
+	paragraphs := 0
+
+	// Depth of the innermost paragraph, or 0 outside one
+	inParagraph := 0
+
+	for depth := 1; depth > 0; {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+
+			switch {
+			case t.Name.Space == nsOffice && t.Name.Local == "annotation":
+				// A comment on the cell, not its value
+				if err = decoder.Skip(); err != nil {
+					return "", err
+				}
+
+				depth--
+			case t.Name.Space == nsText && t.Name.Local == "p":
+				if paragraphs > 0 {
+					sb.WriteByte('\n')
+				}
+
+				paragraphs++
+
+				if inParagraph == 0 {
+					inParagraph = depth
+				}
+			case t.Name.Space == nsText && t.Name.Local == "s":
+				sb.WriteString(strings.Repeat(" ", odsRepeat(t, "c")))
+			case t.Name.Space == nsText && t.Name.Local == "tab":
+				sb.WriteByte('\t')
+			case t.Name.Space == nsText && t.Name.Local == "line-break":
+				sb.WriteByte('\n')
+			}
+		case xml.EndElement:
+			if depth == inParagraph {
+				inParagraph = 0
+			}
+
+			depth--
+		case xml.CharData:
+			if inParagraph > 0 {
+				sb.Write(t)
+			}
+		}
+	}
+
+	if sb.Len() > 0 {
+		return sb.String(), nil
+	}
+
+	// Some writers leave out the displayed text
+	for _, local := range []string{"string-value", "value", "date-value", "time-value", "boolean-value"} {
+		if val := odsAttr(start, nsOffice, local); val != "" {
+			return val, nil
+		}
+	}
+
+	return "", nil
+}
+
+func odsAttr(start xml.StartElement, space, local string) string {
+	for _, attr := range start.Attr {
+		if attr.Name.Space == space && attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+
+	return ""
+}
+
+// odsRepeat() reads a repeat count such as table:number-columns-repeated
+func odsRepeat(start xml.StartElement, local string) int {
+	for _, attr := range start.Attr {
+		if attr.Name.Local == local {
+			if n, err := strconv.Atoi(attr.Value); err == nil && n > 0 {
+				return n
+			}
+		}
+	}
+
+	return 1
+}
@@ -0,0 +1,167 @@
+// Package spreadsheet reads the rows of Excel (.xlsx) and OpenDocument
+// (.ods) workbooks as strings, the way encoding/csv reads a CSV file.
+package spreadsheet
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Reader returns the rows of one sheet as it reads them, so only the
+// row being read is held in memory, along with an .xlsx workbook's
+// shared strings.  Completely empty rows are skipped, as encoding/csv
+// skips empty lines, and empty cells at the end of a row are dropped.
+type Reader struct {
+	// nextRow() returns the next row and its spreadsheet row number,
+	// starting at 1, or io.EOF after the last
+	nextRow func() ([]string, int, error)
+
+	// Row number of the row most recently returned by Read(), or 0
+	line int
+
+	// The error that ended reading, returned by every later Read()
+	err error
+
+	// Closed when reading ends or by Close()
+	closers []io.Closer
+}
+
+// IsWorkbook() is true for file names this package can read
+func IsWorkbook(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".xlsx", ".ods":
+		return true
+	}
+
+	return false
+}
+
+// Open() reads a sheet of an .xlsx or .ods file.  The sheet is chosen
+// by name or, failing that, by its position starting at 1.  An empty
+// sheet chooses the first.
+func Open(name, sheet string) (*Reader, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	var reader *Reader
+
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".xlsx":
+		reader, err = ReadXLSX(f, info.Size(), sheet)
+	case ".ods":
+		reader, err = ReadODS(f, info.Size(), sheet)
+	default:
+		err = fmt.Errorf("not an .xlsx or .ods workbook")
+	}
+
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	reader.closers = append(reader.closers, f)
+
+	return reader, nil
+}
+
+// Read() returns the next row, or io.EOF after the last
+func (r *Reader) Read() ([]string, error) {
+	for r.err == nil {
+		cells, line, err := r.nextRow()
+		if err != nil {
+			r.err = err
+			r.Close()
+
+			break
+		}
+
+		for len(cells) > 0 && cells[len(cells)-1] == "" {
+			cells = cells[:len(cells)-1]
+		}
+
+		if len(cells) > 0 {
+			r.line = line
+			return cells, nil
+		}
+	}
+
+	return nil, r.err
+}
+
+// FieldPos() gives the row and column, both starting at 1, of a field
+// of the row most recently returned by Read().  It mirrors
+// csv.Reader.FieldPos().
+func (r *Reader) FieldPos(field int) (line, column int) {
+	if r.line == 0 {
+		return 0, 0
+	}
+
+	return r.line, field + 1
+}
+
+// Close() closes the workbook.  It is closed anyway once Read() has
+// returned every row or an error.
+func (r *Reader) Close() error {
+	var err error
+
+	for _, closer := range r.closers {
+		if closeErr := closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	r.closers = nil
+
+	if r.err == nil {
+		r.err = io.EOF
+	}
+
+	return err
+}
+
+// chooseSheet() finds a sheet by name or position among names
+func chooseSheet(names []string, sheet string) (int, error) {
+	if len(names) == 0 {
+		return 0, fmt.Errorf("workbook has no sheets")
+	}
+
+	if sheet == "" {
+		return 0, nil
+	}
+
+	for i, name := range names {
+		if name == sheet {
+			return i, nil
+		}
+	}
+
+	if n, err := strconv.Atoi(sheet); err == nil && n >= 1 && n <= len(names) {
+		return n - 1, nil
+	}
+
+	return 0, fmt.Errorf("no sheet %q; the sheets are %q", sheet, names)
+}
+
+// openZipFile() opens a member of a workbook's zip archive
+func openZipFile(archive *zip.Reader, name string) (io.ReadCloser, error) {
+	for _, f := range archive.File {
+		if f.Name == name {
+			return f.Open()
+		}
+	}
+
+	return nil, fmt.Errorf("%s missing from workbook", name)
+}
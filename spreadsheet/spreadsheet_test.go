@@ -0,0 +1,316 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// workbook() zips files into a workbook in memory
+func workbook(t *testing.T, files map[string]string) *bytes.Reader {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	w := zip.NewWriter(&buf)
+
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = f.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return bytes.NewReader(buf.Bytes())
+}
+
+// readAll() returns each row with its line number
+func readAll(t *testing.T, reader *Reader) ([][]string, []int) {
+	t.Helper()
+
+	var rows [][]string
+
+	var lines []int
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return rows, lines
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, row)
+		lines = append(lines, line)
+	}
+}
+
+var xlsxFiles = map[string]string{
+	"xl/workbook.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+  <workbookPr defaultThemeVersion="124226"/>
+  <sheets>
+    <sheet name="Coins" sheetId="1" r:id="rId1"/>
+    <sheet name="Every coin" sheetId="2" r:id="rId2"/>
+  </sheets>
+</workbook>`,
+	"xl/_rels/workbook.xml.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+  <Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="/xl/worksheets/sheet2.xml"/>
+  <Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/sharedStrings" Target="sharedStrings.xml"/>
+  <Relationship Id="rId4" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`,
+	"xl/styles.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+  <numFmts count="2">
+    <numFmt numFmtId="164" formatCode="000000"/>
+    <numFmt numFmtId="165" formatCode="[$-407]dd/mm/yyyy;@"/>
+  </numFmts>
+  <cellXfs count="5">
+    <xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>
+    <xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+    <xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+    <xf numFmtId="22" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+    <xf numFmtId="4" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>
+  </cellXfs>
+</styleSheet>`,
+	"xl/sharedStrings.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" count="6" uniqueCount="6">
+  <si><t>id</t></si>
+  <si><t>weight</t></si>
+  <si><t>title</t></si>
+  <si><t>000123</t></si>
+  <si><r><t>Драхма </t></r><r><rPr><b/></rPr><t>Хосров II</t></r></si>
+  <si><t>source</t></si>
+</sst>`,
+	"xl/worksheets/sheet1.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+  <sheetData>
+    <row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c><c r="D1" t="inlineStr"><is><t>creationtime</t></is></c></row>
+    <row r="2"><c r="A2" s="1"><v>123</v></c><c r="B2"><v>3.7</v></c><c r="C2" t="s"><v>4</v></c><c r="D2" s="2"><v>44178</v></c></row>
+    <row r="3"><c r="B3" s="1"/></row>
+    <row r="5"><c r="A5"><v>58627</v></c><c r="B5" s="4"><v>1234.5</v></c><c r="C5" t="inlineStr"><is><t>AY drachm</t></is></c><c r="D5" s="3"><v>44178.5</v></c></row>
+  </sheetData>
+</worksheet>`,
+	"xl/worksheets/sheet2.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+  <sheetData>
+    <row r="1"><c r="A1" t="s"><v>5</v></c></row>
+    <row r="2"><c r="A2" t="str"><v>Zeno.ru</v></c></row>
+  </sheetData>
+</worksheet>`,
+}
+
+const odsContent = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" office:version="1.2">
+  <office:body>
+    <office:spreadsheet>
+      <table:table table:name="Coins">
+        <table:table-column table:number-columns-repeated="3"/>
+        <table:table-header-rows>
+          <table:table-row>
+            <table:table-cell office:value-type="string"><text:p>id</text:p></table:table-cell>
+            <table:table-cell office:value-type="string"><text:p>weight</text:p></table:table-cell>
+            <table:table-cell office:value-type="string"><text:p>title</text:p></table:table-cell>
+            <table:table-cell table:number-columns-repeated="1021"/>
+          </table:table-row>
+        </table:table-header-rows>
+        <table:table-row>
+          <table:table-cell office:value-type="float" office:value="123"><text:p>000123</text:p></table:table-cell>
+          <table:table-cell office:value-type="float" office:value="3.7"><text:p>3,7</text:p></table:table-cell>
+          <table:table-cell office:value-type="string">
+            <office:annotation><text:p>checked</text:p></office:annotation>
+            <text:p>AY,<text:s/>Sasanian<text:s text:c="2"/><text:span>drachm</text:span></text:p>
+            <text:p>Khusru II</text:p>
+          </table:table-cell>
+        </table:table-row>
+        <table:table-row table:number-rows-repeated="2">
+          <table:table-cell table:number-columns-repeated="1024"/>
+        </table:table-row>
+        <table:table-row>
+          <table:table-cell office:value-type="float" office:value="58627"/>
+          <table:table-cell table:number-columns-repeated="1"/>
+          <table:table-cell office:value-type="string"><text:p>AY drachm</text:p></table:table-cell>
+          <table:table-cell table:number-columns-repeated="1021"/>
+        </table:table-row>
+        <table:table-row table:number-rows-repeated="1048570">
+          <table:table-cell table:number-columns-repeated="1024"/>
+        </table:table-row>
+      </table:table>
+      <table:table table:name="Every coin">
+        <table:table-row>
+          <table:table-cell office:value-type="string"><text:p>source</text:p></table:table-cell>
+        </table:table-row>
+        <table:table-row>
+          <table:table-cell office:value-type="string"><text:p>Zeno.ru</text:p></table:table-cell>
+        </table:table-row>
+      </table:table>
+    </office:spreadsheet>
+  </office:body>
+</office:document-content>`
+
+func TestRead(t *testing.T) {
+	xlsx := workbook(t, xlsxFiles)
+	ods := workbook(t, map[string]string{"content.xml": odsContent, "mimetype": "application/vnd.oasis.opendocument.spreadsheet"})
+
+	testcases := []struct {
+		name  string
+		read  func(sheet string) (*Reader, error)
+		sheet string
+		rows  [][]string
+		lines []int
+	}{
+		{
+			name: "xlsx",
+			read: func(sheet string) (*Reader, error) { return ReadXLSX(xlsx, xlsx.Size(), sheet) },
+			rows: [][]string{
+				{"id", "weight", "title", "creationtime"},
+				{"000123", "3.7", "Драхма Хосров II", "2020-12-13"},
+				{"58627", "1,234.50", "AY drachm", "2020-12-13 12:00:00"},
+			},
+			lines: []int{1, 2, 5},
+		},
+		{
+			name:  "xlsx by name",
+			read:  func(sheet string) (*Reader, error) { return ReadXLSX(xlsx, xlsx.Size(), sheet) },
+			sheet: "Every coin",
+			rows:  [][]string{{"source"}, {"Zeno.ru"}},
+			lines: []int{1, 2},
+		},
+		{
+			name: "ods",
+			read: func(sheet string) (*Reader, error) { return ReadODS(ods, ods.Size(), sheet) },
+			rows: [][]string{
+				{"id", "weight", "title"},
+				{"000123", "3,7", "AY, Sasanian  drachm\nKhusru II"},
+				{"58627", "", "AY drachm"},
+			},
+			lines: []int{1, 2, 5},
+		},
+		{
+			name:  "ods by index",
+			read:  func(sheet string) (*Reader, error) { return ReadODS(ods, ods.Size(), sheet) },
+			sheet: "2",
+			rows:  [][]string{{"source"}, {"Zeno.ru"}},
+			lines: []int{1, 2},
+		},
+	}
+
+	for _, tc := range testcases {
+		reader, err := tc.read(tc.sheet)
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+
+		rows, lines := readAll(t, reader)
+
+		if !reflect.DeepEqual(rows, tc.rows) {
+			t.Errorf("%s: got rows %q, expected %q", tc.name, rows, tc.rows)
+		}
+
+		if !reflect.DeepEqual(lines, tc.lines) {
+			t.Errorf("%s: got lines %v, expected %v", tc.name, lines, tc.lines)
+		}
+	}
+}
+
+func TestMissingSheet(t *testing.T) {
+	xlsx := workbook(t, xlsxFiles)
+
+	_, err := ReadXLSX(xlsx, xlsx.Size(), "Hoards")
+	if err == nil || !strings.Contains(err.Error(), `"Coins" "Every coin"`) {
+		t.Errorf("got %v, expected an error listing the sheets", err)
+	}
+
+	_, err = ReadXLSX(xlsx, xlsx.Size(), "3")
+	if err == nil {
+		t.Errorf("expected an error for sheet 3 of 2")
+	}
+}
+
+// Rows are returned as they are read, before the rest of the sheet
+func TestReadTruncated(t *testing.T) {
+	files := map[string]string{}
+	for name, content := range xlsxFiles {
+		files[name] = content
+	}
+
+	sheet := files["xl/worksheets/sheet2.xml"]
+	files["xl/worksheets/sheet2.xml"] = sheet[:strings.Index(sheet, "Zeno.ru")]
+	xlsx := workbook(t, files)
+
+	reader, err := ReadXLSX(xlsx, xlsx.Size(), "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if row, err := reader.Read(); err != nil || !reflect.DeepEqual(row, []string{"source"}) {
+		t.Errorf("got first row %q, %v", row, err)
+	}
+
+	if _, err = reader.Read(); err == nil || err == io.EOF {
+		t.Errorf("got %v, expected an error for the truncated row", err)
+	}
+
+	if _, err = reader.Read(); err == nil || err == io.EOF {
+		t.Errorf("got %v on reading again, expected the same error", err)
+	}
+}
+
+func TestXLSXColumn(t *testing.T) {
+	for ref, expected := range map[string]int{"A1": 0, "Z9": 25, "AA10": 26, "AB2": 27, "XFD1048576": 16383} {
+		col, err := xlsxColumn(ref)
+		if err != nil || col != expected {
+			t.Errorf("xlsxColumn(%q) = %d, %v; expected %d", ref, col, err, expected)
+		}
+	}
+
+	if _, err := xlsxColumn("1A"); err == nil {
+		t.Errorf("expected an error for 1A")
+	}
+}
+
+func TestFormatXLSXNumber(t *testing.T) {
+	testcases := []struct {
+		val, format string
+		date1904    bool
+		expected    string
+	}{
+		{"123", "000000", false, "000123"},
+		{"123", "General", false, "123"},
+		{"123", "", false, "123"},
+		{"3.14159", "0.00", false, "3.14"},
+		{"-1234567", "#,##0", false, "-1,234,567"},
+		{"0.5", "#.##", false, ".5"},
+		{"0.25", "0%", false, "0.25"},
+		{"44178", "mm-dd-yy", false, "2020-12-13"},
+		{"42716", "d-mmm-yy", true, "2020-12-13"},
+		{"44178.75", "m/d/yy h:mm", false, "2020-12-13 18:00:00"},
+		{"0.5", "h:mm", false, "12:00:00"},
+		{"1.5", "[h]:mm", false, "1.5"},
+		{"59", "yyyy-mm-dd", false, "1900-02-28"},
+		{"abc", "000000", false, "abc"},
+	}
+
+	for _, tc := range testcases {
+		if got := formatXLSXNumber(tc.val, tc.format, tc.date1904); got != tc.expected {
+			t.Errorf("formatXLSXNumber(%q, %q) = %q, expected %q", tc.val, tc.format, got, tc.expected)
+		}
+	}
+}
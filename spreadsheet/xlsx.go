@@ -0,0 +1,440 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Relationship types of the parts of an .xlsx workbook that we read
+const (
+	relWorksheet     = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet"
+	relSharedStrings = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/sharedStrings"
+	relStyles        = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles"
+)
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+	Properties struct {
+		// Dates count from 1904 rather than 1900
+		Date1904 string `xml:"date1904,attr"`
+	} `xml:"workbookPr"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Type   string `xml:"Type,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+// xlsxText is a string that may be split into runs of rich text
+type xlsxText struct {
+	T string `xml:"t"`
+	R []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (text xlsxText) String() string {
+	var sb strings.Builder
+
+	sb.WriteString(text.T)
+
+	for _, run := range text.R {
+		sb.WriteString(run.T)
+	}
+
+	return sb.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+// xlsxStyles holds the number formats of the cell styles
+type xlsxStyles struct {
+	NumFmts []struct {
+		ID   int    `xml:"numFmtId,attr"`
+		Code string `xml:"formatCode,attr"`
+	} `xml:"numFmts>numFmt"`
+	CellXfs []struct {
+		NumFmtID int `xml:"numFmtId,attr"`
+	} `xml:"cellXfs>xf"`
+}
+
+// xlsxRow is a <row> of a worksheet's <sheetData>
+type xlsxRow struct {
+	R     int `xml:"r,attr"`
+	Cells []struct {
+		R  string   `xml:"r,attr"`
+		T  string   `xml:"t,attr"`
+		S  int      `xml:"s,attr"`
+		V  string   `xml:"v"`
+		Is xlsxText `xml:"is"`
+	} `xml:"c"`
+}
+
+// ReadXLSX() reads a sheet of an Excel workbook.  Text is read as typed.
+// Numbers are written in their number format if it is a plain one such
+// as "000000" or "#,##0.00", and dates and times as "2006-01-02 15:04:05";
+// other numbers are read as Excel stores them.
+func ReadXLSX(r io.ReaderAt, size int64, sheet string) (*Reader, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	var workbook xlsxWorkbook
+	if err = decodeZipXML(archive, "xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+
+	var rels xlsxRelationships
+	if err = decodeZipXML(archive, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(workbook.Sheets))
+	for i, s := range workbook.Sheets {
+		names[i] = s.Name
+	}
+
+	i, err := chooseSheet(names, sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	var sheetPart string
+
+	var shared []string
+
+	// Number format of each cell style
+	var formats []string
+
+	for _, rel := range rels.Relationships {
+		switch {
+		case rel.Type == relWorksheet && rel.ID == workbook.Sheets[i].ID:
+			sheetPart = xlsxPart(rel.Target)
+		case rel.Type == relSharedStrings:
+			var sst xlsxSharedStrings
+			if err = decodeZipXML(archive, xlsxPart(rel.Target), &sst); err != nil {
+				return nil, err
+			}
+
+			for _, item := range sst.Items {
+				shared = append(shared, item.String())
+			}
+		case rel.Type == relStyles:
+			var styles xlsxStyles
+			if err = decodeZipXML(archive, xlsxPart(rel.Target), &styles); err != nil {
+				return nil, err
+			}
+
+			formats = styles.formats()
+		}
+	}
+
+	if sheetPart == "" {
+		return nil, fmt.Errorf("sheet %q has no worksheet", names[i])
+	}
+
+	f, err := openZipFile(archive, sheetPart)
+	if err != nil {
+		return nil, err
+	}
+
+	date1904 := workbook.Properties.Date1904 == "1" || workbook.Properties.Date1904 == "true"
+	decoder := xml.NewDecoder(f)
+	line := 0
+
+	nextRow := func() ([]string, int, error) {
+		for {
+			token, err := decoder.Token()
+			if err == io.EOF {
+				return nil, 0, io.EOF
+			}
+
+			if err != nil {
+				return nil, 0, fmt.Errorf("%s: %w", sheetPart, err)
+			}
+
+			start, ok := token.(xml.StartElement)
+			if !ok || start.Name.Local != "row" {
+				continue
+			}
+
+			var row xlsxRow
+			if err = decoder.DecodeElement(&row, &start); err != nil {
+				return nil, 0, fmt.Errorf("%s: %w", sheetPart, err)
+			}
+
+			// Rows and cells may leave out their position, meaning "next"
+			line++
+			if row.R != 0 {
+				line = row.R
+			}
+
+			cells, err := row.values(shared, formats, date1904)
+			if err != nil {
+				return nil, 0, fmt.Errorf("%s: %w", sheetPart, err)
+			}
+
+			return cells, line, nil
+		}
+	}
+
+	return &Reader{nextRow: nextRow, closers: []io.Closer{f}}, nil
+}
+
+// values() returns the text of each cell of a row
+func (row xlsxRow) values(shared, formats []string, date1904 bool) ([]string, error) {
+	var cells []string
+
+	for _, cell := range row.Cells {
+		col := len(cells)
+		if cell.R != "" {
+			var err error
+			if col, err = xlsxColumn(cell.R); err != nil {
+				return nil, err
+			}
+		}
+
+		for len(cells) <= col {
+			cells = append(cells, "")
+		}
+
+		switch cell.T {
+		case "s":
+			n, err := strconv.Atoi(cell.V)
+			if err != nil || n < 0 || n >= len(shared) {
+				return nil, fmt.Errorf("cell %s: bad shared string %q", cell.R, cell.V)
+			}
+
+			cells[col] = shared[n]
+		case "inlineStr":
+			cells[col] = cell.Is.String()
+		case "b":
+			cells[col] = map[string]string{"0": "FALSE", "1": "TRUE"}[cell.V]
+		case "", "n":
+			format := ""
+			if cell.S >= 0 && cell.S < len(formats) {
+				format = formats[cell.S]
+			}
+
+			cells[col] = formatXLSXNumber(cell.V, format, date1904)
+		default:
+			cells[col] = cell.V
+		}
+	}
+
+	return cells, nil
+}
+
+// Built-in number formats that we format.  Format 14 is the short date
+// of the reader's locale.
+var xlsxBuiltinFormats = map[int]string{
+	1:  "0",
+	2:  "0.00",
+	3:  "#,##0",
+	4:  "#,##0.00",
+	14: "mm-dd-yy",
+	15: "d-mmm-yy",
+	16: "d-mmm",
+	17: "mmm-yy",
+	18: "h:mm AM/PM",
+	19: "h:mm:ss AM/PM",
+	20: "h:mm",
+	21: "h:mm:ss",
+	22: "m/d/yy h:mm",
+	45: "mm:ss",
+	47: "mmss.0",
+}
+
+// formats() returns the number format code of each cell style
+func (styles xlsxStyles) formats() []string {
+	custom := map[int]string{}
+	for _, numFmt := range styles.NumFmts {
+		custom[numFmt.ID] = numFmt.Code
+	}
+
+	formats := make([]string, len(styles.CellXfs))
+
+	for i, xf := range styles.CellXfs {
+		if code, ok := custom[xf.NumFmtID]; ok {
+			formats[i] = code
+		} else {
+			formats[i] = xlsxBuiltinFormats[xf.NumFmtID]
+		}
+	}
+
+	return formats
+}
+
+var (
+	// Quoted text, escaped characters and [colors] or [conditions]
+	formatLiteralRE = regexp.MustCompile(`"[^"]*"|\\.|\[[^\]]*\]`)
+	plainNumberRE   = regexp.MustCompile(`^([#0,]*)(?:\.([#0]+))?$`)
+	dateTokenRE     = regexp.MustCompile(`[yd]`)
+	timeTokenRE     = regexp.MustCompile(`[hs]`)
+)
+
+// formatXLSXNumber() formats a number stored in a cell as its number
+// format displays it.  Dates and times are written in a form that
+// the converter parses rather than as displayed.
+func formatXLSXNumber(val, format string, date1904 bool) string {
+	number, err := strconv.ParseFloat(val, 64)
+	if err != nil || format == "" {
+		return val
+	}
+
+	// Only the format for positive numbers matters for dates and IDs
+	section := strings.SplitN(format, ";", 2)[0]
+
+	// Elapsed times such as [h]:mm are durations, not dates
+	if strings.Contains(section, "[h") || strings.Contains(section, "[m") || strings.Contains(section, "[s") {
+		return val
+	}
+
+	tokens := strings.ToLower(formatLiteralRE.ReplaceAllString(section, ""))
+	hasDate, hasTime := dateTokenRE.MatchString(tokens), timeTokenRE.MatchString(tokens)
+
+	switch {
+	case hasDate || hasTime:
+		return formatXLSXDate(number, hasDate, hasTime, date1904)
+	case plainNumberRE.MatchString(section) && strings.ContainsAny(section, "#0"):
+		m := plainNumberRE.FindStringSubmatch(section)
+		return formatPlainNumber(number, m[1], m[2])
+	}
+
+	return val
+}
+
+// formatXLSXDate() turns the days since the workbook's epoch into a date,
+// time, or both
+func formatXLSXDate(serial float64, hasDate, hasTime, date1904 bool) string {
+	// Excel counts 29 February 1900, which didn't exist
+	epoch := time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+	if date1904 {
+		epoch = time.Date(1904, time.January, 1, 0, 0, 0, 0, time.UTC)
+	} else if serial < 61 {
+		epoch = epoch.AddDate(0, 0, 1)
+	}
+
+	days := math.Floor(serial)
+	seconds := math.Round((serial - days) * 24 * 60 * 60)
+	t := epoch.AddDate(0, 0, int(days)).Add(time.Duration(seconds) * time.Second)
+
+	switch {
+	case !hasDate:
+		return t.Format("15:04:05")
+	case !hasTime && seconds == 0:
+		return t.Format("2006-01-02")
+	}
+
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// formatPlainNumber() formats a number with a format such as "000000" or
+// "#,##0.00": "0" is a digit that is always shown, "#" one that is shown
+// if needed, and a comma groups thousands
+func formatPlainNumber(number float64, integer, fraction string) string {
+	minInteger := strings.Count(integer, "0")
+	minFraction := strings.Count(fraction, "0")
+
+	digits := strconv.FormatFloat(math.Abs(number), 'f', len(fraction), 64)
+	intDigits, fracDigits := digits, ""
+
+	if i := strings.IndexByte(digits, '.'); i >= 0 {
+		intDigits, fracDigits = digits[:i], digits[i+1:]
+	}
+
+	for len(fracDigits) > minFraction && strings.HasSuffix(fracDigits, "0") {
+		fracDigits = fracDigits[:len(fracDigits)-1]
+	}
+
+	if intDigits == "0" && minInteger == 0 {
+		intDigits = ""
+	}
+
+	for len(intDigits) < minInteger {
+		intDigits = "0" + intDigits
+	}
+
+	if strings.Contains(integer, ",") {
+		for i := len(intDigits) - 3; i > 0; i -= 3 {
+			intDigits = intDigits[:i] + "," + intDigits[i:]
+		}
+	}
+
+	var sb strings.Builder
+
+	if number < 0 && strings.Trim(intDigits+fracDigits, "0,") != "" {
+		sb.WriteString("-")
+	}
+
+	sb.WriteString(intDigits)
+
+	if fracDigits != "" {
+		sb.WriteString(".")
+		sb.WriteString(fracDigits)
+	}
+
+	return sb.String()
+}
+
+// xlsxPart() resolves a relationship target against the xl/ directory
+func xlsxPart(target string) string {
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(target, "/")
+	}
+
+	return path.Join("xl", target)
+}
+
+// xlsxColumn() returns the zero-based column of a cell reference such as "AB12"
+func xlsxColumn(ref string) (int, error) {
+	col := 0
+
+	for i, c := range ref {
+		switch {
+		case c >= 'A' && c <= 'Z':
+			col = col*26 + int(c-'A'+1)
+		case c >= '0' && c <= '9' && i > 0:
+			return col - 1, nil
+		default:
+			return 0, fmt.Errorf("bad cell reference %q", ref)
+		}
+	}
+
+	if col == 0 {
+		return 0, fmt.Errorf("bad cell reference %q", ref)
+	}
+
+	return col - 1, nil
+}
+
+func decodeZipXML(archive *zip.Reader, name string, v interface{}) error {
+	f, err := openZipFile(archive, name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err = xml.NewDecoder(f).Decode(v); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	return nil
+}